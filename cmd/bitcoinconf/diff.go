@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// runDiff implements the diff command: it reports the profiles added,
+// removed, and changed between two output files, matched by ID.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: bitcoinconf diff [flags] OLD NEW")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "text", "diff format: text (a summary line, then +added, -removed, and ~changed profiles with their changed fields) or json")
+	input := addInputFlags(fs)
+
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format != "text" && *format != "json" {
+		fatal("flag error", "err", fmt.Sprintf("unknown --format %q (want text or json)", *format))
+	}
+
+	old, _, err := input.read(fs.Arg(0))
+	if err != nil {
+		fatal("read input error", "path", fs.Arg(0), "err", err)
+	}
+	fresh, _, err := input.read(fs.Arg(1))
+	if err != nil {
+		fatal("read input error", "path", fs.Arg(1), "err", err)
+	}
+
+	d := scraper.DiffProfiles(old, fresh)
+	if *format == "json" {
+		err = writeDiffJSON(os.Stdout, d)
+	} else {
+		err = writeDiffText(os.Stdout, d, len(old), len(fresh))
+	}
+	if err != nil {
+		fatal("write diff error", "err", err)
+	}
+}
+
+// writeDiffText writes d for reading: a summary line, then one line per
+// added or removed profile and, for each changed one, a line per field.
+func writeDiffText(w io.Writer, d scraper.ProfileDiff, oldCount, newCount int) error {
+	fmt.Fprintf(w, "%d added, %d removed, %d changed (%d -> %d profiles)\n", len(d.Added), len(d.Removed), len(d.Changed), oldCount, newCount)
+	if d.Unkeyed > 0 {
+		fmt.Fprintf(w, "not compared: %d profiles without an ID\n", d.Unkeyed)
+	}
+
+	for _, p := range d.Added {
+		fmt.Fprintf(w, "+ %s\n", profileLabel(p.Name, p.ID, p.Company))
+	}
+	for _, p := range d.Removed {
+		fmt.Fprintf(w, "- %s\n", profileLabel(p.Name, p.ID, p.Company))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ %s\n", profileLabel(c.Name, c.ID, ""))
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", f.Field, diffValue(f.Old), diffValue(f.New))
+		}
+	}
+	return nil
+}
+
+// writeDiffJSON writes d as one indented JSON object.
+func writeDiffJSON(w io.Writer, d scraper.ProfileDiff) error {
+	// Empty lists rather than null, so consumers can always iterate.
+	if d.Added == nil {
+		d.Added = []scraper.Profile{}
+	}
+	if d.Removed == nil {
+		d.Removed = []scraper.Profile{}
+	}
+	if d.Changed == nil {
+		d.Changed = []scraper.ProfileChange{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// profileLabel names a profile in diff output: "Name (id ID, Company)".
+func profileLabel(name, id, company string) string {
+	details := []string{"id " + id}
+	if company != "" {
+		details = append(details, company)
+	}
+	if name == "" {
+		name = "(no name)"
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+// diffValue formats a FieldChange value compactly as JSON, or "(none)" for
+// an empty field.
+func diffValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
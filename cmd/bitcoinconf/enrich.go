@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+
+	"bitcoinconferencescraper/internal/config"
+)
+
+// runEnrich implements the enrich command: it adds LinkedIn URLs and, with
+// --twitter, Twitter accounts to the profiles in an existing file, which
+// can be a CSV contact list that never came from Brella. Only the search
+// API configuration is needed; no Brella settings are read.
+func runEnrich(args []string) {
+	fs := flag.NewFlagSet("enrich", flag.ExitOnError)
+	inputPath := fs.String("in", "", "input file path (JSON, NDJSON, or CSV; see --input-format) with the profiles to enrich (required)")
+	input := addInputFlags(fs)
+	common := addCommonFlags(fs, "")
+
+	fs.Parse(args)
+	logger := common.setup()
+
+	if *inputPath == "" {
+		fatal("flag error", "err", "--in is required")
+	}
+
+	cfg, err := config.SearchFromEnv()
+	if err != nil {
+		fatal("config error", "err", err)
+	}
+	secrets.set(cfg.Secrets())
+
+	if !*common.enrichLinkedIn && !*common.enrichTwitter && !*common.verifyURLs {
+		fatal("flag error", "err", "nothing to enrich: --linkedin=false and neither --twitter nor --verify-urls is set")
+	}
+
+	linkedinMatcher := common.newMatcher(common.searchClient(cfg), cfg, newRateLimiter(cfg))
+	if (*common.enrichLinkedIn || *common.enrichTwitter) && !linkedinMatcher.Enabled() {
+		fatal("config error", "err", "BITCONF_SEARCH_PROVIDER=google needs BITCONF_SEARCH_API_KEY and BITCONF_SEARCH_ENGINE_ID to enrich profiles")
+	}
+
+	ctx, stop := signalContext(*common.maxRuntime)
+	defer stop()
+
+	logger.Info("loading profiles to enrich", "path", *inputPath)
+	profiles, events, err := input.read(*inputPath)
+	if err != nil {
+		fatal("read input error", "err", err)
+	}
+	common.events = events
+
+	profiles = common.finish(ctx, linkedinMatcher, nil, profiles)
+	common.summary("wrote %d profiles to %s", len(profiles), outputName(*common.outputPath))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// keywordFilter returns a predicate that keeps profiles whose Company
+// contains any of companies and whose Title contains any of titles, both
+// case-insensitively. An empty keyword list matches everything. It returns
+// nil if neither list has keywords.
+func keywordFilter(companies, titles []string) func(scraper.Profile) bool {
+	if len(companies) == 0 && len(titles) == 0 {
+		return nil
+	}
+
+	return func(p scraper.Profile) bool {
+		return containsAny(p.Company, companies) && containsAny(p.Title, titles)
+	}
+}
+
+func containsAny(s string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+	s = strings.ToLower(s)
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKeywords splits a comma-separated flag value into lowercased,
+// trimmed, non-empty keywords.
+func splitKeywords(v string) []string {
+	var out []string
+	for _, k := range strings.Split(v, ",") {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// filterProfiles returns the profiles that keep accepts.
+func filterProfiles(profiles []scraper.Profile, keep func(scraper.Profile) bool) []scraper.Profile {
+	out := profiles[:0:0]
+	for _, p := range profiles {
+		if keep(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// companyDrop counts the profiles limitPerCompany dropped for a company.
+type companyDrop struct {
+	Company string
+	Dropped int
+}
+
+// limitPerCompany keeps the first limit profiles of each company and drops
+// the rest, returning the kept profiles and, in the order companies first
+// went over the limit, how many were dropped for each, named as their
+// first profile spells them. Companies are
+// compared case-insensitively with whitespace collapsed; profiles without
+// a company are all kept. A limit <= 0 keeps everything.
+func limitPerCompany(profiles []scraper.Profile, limit int) ([]scraper.Profile, []companyDrop) {
+	if limit <= 0 {
+		return profiles, nil
+	}
+
+	seen := make(map[string]int)
+	names := make(map[string]string)
+	dropIdx := make(map[string]int)
+	var drops []companyDrop
+	out := profiles[:0:0]
+	for _, p := range profiles {
+		key := strings.ToLower(strings.Join(strings.Fields(p.Company), " "))
+		if key == "" {
+			out = append(out, p)
+			continue
+		}
+		if seen[key]++; seen[key] <= limit {
+			if seen[key] == 1 {
+				names[key] = strings.TrimSpace(p.Company)
+			}
+			out = append(out, p)
+			continue
+		}
+		i, ok := dropIdx[key]
+		if !ok {
+			i = len(drops)
+			dropIdx[key] = i
+			drops = append(drops, companyDrop{Company: names[key]})
+		}
+		drops[i].Dropped++
+	}
+	return out, drops
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/export"
+	"bitcoinconferencescraper/internal/httpcache"
+	"bitcoinconferencescraper/internal/httplog"
+	"bitcoinconferencescraper/internal/linkedin"
+	"bitcoinconferencescraper/internal/metrics"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// commonFlags holds the flags every command accepts: output, HTTP, and
+// logging settings.
+type commonFlags struct {
+	outputPath *string
+	format     *string
+	appendOut  *bool
+	vcardSplit *bool
+	timeoutSec *int
+	cacheDir   *string
+	cacheTTL   *time.Duration
+	debugHTTP  *bool
+
+	proxies        stringList
+	searchProxies  stringList
+	queryTemplates stringList
+	proxyURLs      []*url.URL
+	searchURLs     []*url.URL
+	validate       *bool
+	dedupBy        *string
+	fields         *string
+	selected       export.Fields
+
+	enrichLinkedIn        *bool
+	enrichTwitter         *bool
+	searchConcurrency     *int
+	continueOnSearchError *bool
+	stopAfterNoResults    *int
+	verifyNames           *bool
+	backfill              *bool
+	includeCompanyPages   *bool
+	onlyMissingLinkedIn   *bool
+	noMatchCache          *string
+	noMatchTTL            *time.Duration
+	verifyURLs            *bool
+	verifyDelay           *time.Duration
+	review                *bool
+	reviewDecisions       *string
+
+	sheetsID          *string
+	sheetsCredentials *string
+	sheetsTab         *string
+	sheetsAppend      *bool
+
+	report       *string
+	reportFormat *string
+	reportTop    *int
+	reports      []string
+
+	// events, if not nil, are written with the profiles in a JSON
+	// wrapper: fetched with --event-info or read from a wrapped input.
+	events []scraper.Event
+
+	maxRuntime *time.Duration
+
+	secretsFile *string
+
+	metricsAddr *string
+	metrics     *metrics.Registry
+
+	quiet     *bool
+	logLevel  *string
+	logFormat *string
+}
+
+// addCommonFlags registers the shared flags on fs. formatNote, if not
+// empty, is appended to the --format help.
+func addCommonFlags(fs *flag.FlagSet, formatNote string) *commonFlags {
+	formatHelp := "output format: json, csv, ndjson, or vcard (contacts for importing into contacts apps and CRMs; see --vcard-split)"
+	if formatNote != "" {
+		formatHelp += " (" + formatNote + ")"
+	}
+
+	c := &commonFlags{
+		outputPath:            fs.String("out", "profiles.json", `output file path, or "-" for stdout (summaries then go to stderr)`),
+		format:                fs.String("format", "json", formatHelp),
+		appendOut:             fs.Bool("append", false, "add to the existing --out file instead of replacing it: json is merged with it by ID, ndjson gets lines appended for IDs not in it yet (not supported for csv or stdout)"),
+		vcardSplit:            fs.Bool("vcard-split", false, "with --format vcard, write one .vcf file per profile, named after its ID, into the --out directory instead of all contacts to one file"),
+		timeoutSec:            fs.Int("timeout-sec", 30, "HTTP client timeout in seconds; search requests use BITCONF_SEARCH_TIMEOUT_SEC (or BITCONF_SEARCH_REQUEST_TIMEOUT_MS) instead when it is set"),
+		cacheDir:              fs.String("cache-dir", "", "optional directory for caching successful GET responses (Brella and search API) between runs; request delays still apply"),
+		cacheTTL:              fs.Duration("cache-ttl", 24*time.Hour, "how long cached responses are reused before being refetched (0 = forever)"),
+		debugHTTP:             fs.Bool("debug-http", false, "log every Brella and search request sent (cache hits aren't) with its method, URL, status, duration, headers, and the first 2 KB of each body, credentials masked; implies --log-level debug"),
+		dedupBy:               fs.String("dedup-by", "id", "how duplicate profiles are merged before enrichment: id (the same ID, or the same name at different events) or name (also the same name and company, ignoring case and accents; LinkedIn candidates are combined)"),
+		fields:                fs.String("fields", "", "comma-separated profile fields to write, e.g. name,company,linkedin_url (default all)"),
+		validate:              fs.Bool("validate", false, "validate profiles before enrichment; on hard errors (empty names, malformed LinkedIn URLs, duplicate IDs) write the output unenriched and exit non-zero"),
+		enrichLinkedIn:        fs.Bool("linkedin", true, "search for LinkedIn URLs of profiles without one"),
+		enrichTwitter:         fs.Bool("twitter", false, "search for Twitter/X accounts of profiles without one"),
+		searchConcurrency:     fs.Int("search-concurrency", 1, "number of LinkedIn searches in flight at once; BITCONF_SEARCH_DELAY_MS and BITCONF_RATE_LIMIT_RPS still cap the overall rate"),
+		verifyNames:           fs.Bool("verify-names", false, "only accept a LinkedIn profile as the match if its URL slug fits the person's name; others are kept as possible URLs"),
+		backfill:              fs.Bool("backfill-from-linkedin", false, "fill blank company and title fields from the search result of a LinkedIn match whose title names the person; filled fields are listed in the profile's sources"),
+		onlyMissingLinkedIn:   fs.Bool("only-missing-linkedin", true, "only search for profiles with no LinkedIn URL and no possible ones either; pass false to also search profiles that only have possible URLs (costs search quota)"),
+		includeCompanyPages:   fs.Bool("include-company-pages", false, "keep linkedin.com company pages found by search among a profile's possible LinkedIn URLs; by default only personal /in/ and legacy /pub/ profiles are kept"),
+		noMatchCache:          fs.String("no-match-cache", "", "optional JSON file remembering profile IDs whose LinkedIn search found nothing, so later runs skip them until --no-match-ttl has passed"),
+		noMatchTTL:            fs.Duration("no-match-ttl", 30*24*time.Hour, "how long a --no-match-cache entry keeps a profile from being searched again (0 = forever)"),
+		verifyURLs:            fs.Bool("verify-urls", false, "after enrichment, request each LinkedIn URL not checked before and record its HTTP status; URLs that 404 are demoted to possible URLs (adds a request per profile, and LinkedIn may rate-limit them)"),
+		verifyDelay:           fs.Duration("verify-delay", 2*time.Second, "least time between --verify-urls requests"),
+		review:                fs.Bool("review", false, "after enrichment, ask on the terminal which LinkedIn URL is right for each profile with several candidates that fit its name about equally well"),
+		reviewDecisions:       fs.String("review-decisions", "review-decisions.json", "JSON file where --review saves each pick (or skip) by profile ID, so later runs apply it instead of asking again"),
+		stopAfterNoResults:    fs.Int("stop-after-no-results", 0, "stop LinkedIn enrichment once this many searches in a row find nothing, which usually means the search API is returning empty pages (0 = never); those profiles stay unsearched"),
+		continueOnSearchError: fs.Bool("continue-on-search-error", false, "log failed LinkedIn searches and keep going instead of stopping at the first one; failed profiles are retried on the next run"),
+
+		sheetsID:          fs.String("sheets-id", "", "optional Google Sheets spreadsheet ID; the profiles are also written there, in the CSV column layout"),
+		sheetsCredentials: fs.String("sheets-credentials", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"), "service account key file for --sheets-id (default $GOOGLE_APPLICATION_CREDENTIALS); share the spreadsheet with its client_email"),
+		sheetsTab:         fs.String("sheets-tab", "Sheet1", "sheet (tab) name to write to"),
+		sheetsAppend:      fs.Bool("sheets-append", false, "append profiles whose ID isn't in the sheet yet instead of clearing and rewriting it"),
+
+		report:       fs.String("report", "", "comma-separated breakdowns to print after writing the output: companies, locations, countries (profile counts per value, largest first)"),
+		reportFormat: fs.String("report-format", "table", "--report format: table or json"),
+		reportTop:    fs.Int("report-top", 20, "groups listed per --report breakdown (0 = all)"),
+
+		maxRuntime: fs.Duration("max-runtime", 0, "stop the run after this long (e.g. 2h) and write whatever has been collected, exiting non-zero; the checkpoint is saved as on Ctrl-C (0 = no limit)"),
+
+		secretsFile: fs.String("secrets-file", "", "optional NAME=value file (keep it out of version control) setting the credential variables not already in the environment: "+strings.Join(config.SecretVars, ", ")+"; each can also be read from the file named by NAME_FILE"),
+
+		metricsAddr: fs.String("metrics-addr", "", "optional listen address such as :9090 for serving Prometheus metrics at /metrics while the run lasts"),
+
+		quiet:     fs.Bool("quiet", false, "only log warnings and errors (overrides a lower --log-level)"),
+		logLevel:  fs.String("log-level", "info", "log level: debug, info, warn, or error"),
+		logFormat: fs.String("log-format", "text", "log format: text or json"),
+	}
+	fs.Var(&c.proxies, "proxy", "proxy URL (http://, https://, socks5://, or socks5h://, credentials as user:pass@); repeat to rotate between proxies per request (default from $HTTPS_PROXY etc.)")
+	fs.Var(&c.queryTemplates, "query-template", `LinkedIn search query as a Go template over the profile's fields, e.g. '{{quote .Name}} {{quote .CleanCompany}} {{.Location}} site:linkedin.com/in'; repeat to try several in order (default $BITCONF_SEARCH_QUERIES, one per line, else the built-in queries)`)
+	fs.Var(&c.searchProxies, "search-proxy", "proxy URL for search requests only, repeatable like --proxy (default: the --proxy list)")
+	return c
+}
+
+// setup checks the shared flags once parsed, installs the logger they
+// describe as the slog default, and returns it. Invalid flags are fatal.
+func (c *commonFlags) setup() *slog.Logger {
+	level := *c.logLevel
+	if *c.debugHTTP {
+		level = "debug"
+	}
+	logger, err := newLogger(level, *c.logFormat, *c.quiet && !*c.debugHTTP)
+	if err != nil {
+		fatal("flag error", "err", err)
+	}
+	slog.SetDefault(logger)
+
+	if *c.secretsFile != "" {
+		if err := config.LoadSecretsFile(*c.secretsFile); err != nil {
+			fatal("secrets file error", "err", err)
+		}
+	}
+	if err := export.CheckFormat(*c.format); err != nil {
+		fatal("flag error", "err", err)
+	}
+	if *c.dedupBy != "id" && *c.dedupBy != "name" {
+		fatal("flag error", "err", fmt.Errorf("unknown --dedup-by %q (want id or name)", *c.dedupBy))
+	}
+	if *c.review && !isTerminal(os.Stdin) {
+		fatal("flag error", "err", "--review needs a terminal on stdin to ask on")
+	}
+	if *c.appendOut && (*c.format == "csv" || *c.format == "vcard" || *c.outputPath == stdoutPath) {
+		fatal("flag error", "err", "--append needs a json or ndjson output file")
+	}
+	if *c.vcardSplit && (*c.format != "vcard" || *c.outputPath == stdoutPath) {
+		fatal("flag error", "err", "--vcard-split needs --format vcard and an --out directory")
+	}
+	if c.proxyURLs, err = config.ParseProxies(c.proxies); err != nil {
+		fatal("flag error", "err", fmt.Errorf("--proxy: %w", err))
+	}
+	c.searchURLs = c.proxyURLs
+	if len(c.searchProxies) > 0 {
+		if c.searchURLs, err = config.ParseProxies(c.searchProxies); err != nil {
+			fatal("flag error", "err", fmt.Errorf("--search-proxy: %w", err))
+		}
+	}
+	if c.selected, err = export.ParseFields(*c.fields); err != nil {
+		fatal("flag error", "err", fmt.Errorf("--fields: %w", err))
+	}
+	if *c.sheetsAppend && c.selected != nil && !c.selected["id"] {
+		fatal("flag error", "err", "--sheets-append needs id in --fields to tell which profiles are new")
+	}
+	if c.reports, err = parseReports(*c.report); err != nil {
+		fatal("flag error", "err", fmt.Errorf("--report: %w", err))
+	}
+	if *c.reportFormat != "table" && *c.reportFormat != "json" {
+		fatal("flag error", "err", fmt.Errorf("unknown --report-format %q (want table or json)", *c.reportFormat))
+	}
+	if *c.metricsAddr != "" {
+		c.metrics = &metrics.Registry{}
+		if err := serveMetrics(*c.metricsAddr, c.metrics); err != nil {
+			fatal("metrics server error", "err", err)
+		}
+		logger.Info("serving metrics", "addr", *c.metricsAddr, "path", "/metrics")
+	}
+	return logger
+}
+
+// httpClient returns the HTTP client for Brella requests, going through the
+// --proxy list, logging each request with --debug-http, and wrapped in a
+// response cache when --cache-dir is set.
+func (c *commonFlags) httpClient(cfg config.Config) *http.Client {
+	return c.newHTTPClient(cfg, time.Duration(*c.timeoutSec)*time.Second, c.proxyURLs)
+}
+
+// searchClient is like httpClient but for search requests, going through
+// the --search-proxy list instead when one is given. Its timeout is the
+// configured search timeout rather than --timeout-sec, if there is one, so
+// searches can be given longer than Brella requests.
+func (c *commonFlags) searchClient(cfg config.Config) *http.Client {
+	timeout := time.Duration(*c.timeoutSec) * time.Second
+	if cfg.SearchRequestTimeout > 0 {
+		timeout = cfg.SearchRequestTimeout
+	}
+	return c.newHTTPClient(cfg, timeout, c.searchURLs)
+}
+
+func (c *commonFlags) newHTTPClient(cfg config.Config, timeout time.Duration, proxies []*url.URL) *http.Client {
+	client := config.NewHTTPClient(timeout, proxies...)
+	if *c.debugHTTP {
+		client.Transport = &httplog.Transport{Next: client.Transport}
+	}
+	if *c.cacheDir != "" {
+		client.Transport = &httpcache.Transport{
+			Dir:          *c.cacheDir,
+			TTL:          *c.cacheTTL,
+			MaxBodyBytes: cfg.MaxResponseBytes,
+			Next:         client.Transport,
+		}
+	}
+	return client
+}
+
+// enriches reports whether finish will search for anything with m, given
+// --linkedin and --twitter, or check URLs with --verify-urls.
+func (c *commonFlags) enriches(m *linkedin.Matcher) bool {
+	return m.Enabled() && (*c.enrichLinkedIn || *c.enrichTwitter) || *c.verifyURLs
+}
+
+// newMatcher returns the LinkedIn matcher configured by cfg and the shared
+// search flags. limiter, if not nil, caps its request rate.
+func (c *commonFlags) newMatcher(httpClient *http.Client, cfg config.Config, limiter *rate.Limiter) *linkedin.Matcher {
+	m := linkedin.NewMatcher(httpClient, cfg)
+	m.Logger = slog.Default()
+	m.Limiter = limiter
+	m.Concurrency = *c.searchConcurrency
+	m.ContinueOnError = *c.continueOnSearchError
+	m.StopAfterNoResults = *c.stopAfterNoResults
+	m.VerifyNames = *c.verifyNames
+	m.VerifyDelay = *c.verifyDelay
+	m.Backfill = *c.backfill
+	m.IncludeCompanyPages = *c.includeCompanyPages
+	m.SearchCandidates = !*c.onlyMissingLinkedIn
+	templates := cfg.SearchQueryTemplates
+	if len(c.queryTemplates) > 0 {
+		templates = c.queryTemplates
+	}
+	queries, err := linkedin.ParseQueryTemplates(templates)
+	if err != nil {
+		fatal("search query error", "err", err)
+	}
+	m.QueryTemplates = queries
+	if *c.noMatchCache != "" {
+		cache, err := linkedin.LoadNoMatchCache(*c.noMatchCache, *c.noMatchTTL)
+		if err != nil {
+			fatal("no-match cache error", "err", err)
+		}
+		m.NoMatchCache = cache
+	}
+	if c.metrics != nil {
+		m.Metrics = c.metrics
+	}
+	if m.Enabled() && (*c.enrichLinkedIn || *c.enrichTwitter) {
+		// "default" means BITCONF_SEARCH_PROVIDER was unset and the provider
+		// was picked by whether the Google credentials are set.
+		m.Logger.Info("using search provider", "provider", cfg.SearchProvider, "default", cfg.SearchProviderAuto)
+	}
+	return m
+}
+
+// serveMetrics starts serving reg at /metrics on addr in the background.
+// Listen errors are returned; the server then runs until the process
+// exits.
+func serveMetrics(addr string, reg *metrics.Registry) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reg)
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			slog.Default().Warn("metrics server stopped", "err", err)
+		}
+	}()
+	return nil
+}
+
+// stringList is a flag.Value collecting every value of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// writeOutput writes profiles to --out in the chosen format and fields,
+// or adds them to it with --append: NDJSON output gets a line appended for
+// each profile whose ID isn't in the file yet, without rewriting it; JSON
+// output is read, merged with profiles by ID, and rewritten. JSON output
+// wraps the profiles with c.events when there are any.
+func (c *commonFlags) writeOutput(profiles []scraper.Profile) error {
+	if *c.appendOut && *c.format == "ndjson" {
+		return appendProfilesNDJSON(*c.outputPath, c.selected, profiles)
+	}
+
+	events := c.events
+	if *c.appendOut {
+		var existing []scraper.Event
+		var err error
+		if profiles, existing, err = mergeExisting(*c.outputPath, profiles); err != nil {
+			return err
+		}
+		events = mergeEvents(existing, events)
+	}
+
+	switch *c.format {
+	case "json":
+		return export.JSON{Path: *c.outputPath, Fields: c.selected, Events: events}.Write(profiles)
+	case "vcard":
+		return export.VCard{Path: *c.outputPath, Fields: c.selected, Split: *c.vcardSplit}.Write(profiles)
+	}
+	e, err := export.New(*c.format, *c.outputPath, c.selected)
+	if err != nil {
+		return err
+	}
+	return e.Write(profiles)
+}
+
+// summary prints a run summary line. It goes to stdout, unless the profiles
+// themselves are being written there, in which case it goes to stderr so
+// stdout holds nothing but data.
+func (c *commonFlags) summary(format string, args ...any) {
+	w := os.Stdout
+	if *c.outputPath == stdoutPath {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format+"\n", args...)
+}
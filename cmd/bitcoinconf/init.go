@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bitcoinconferencescraper/internal/config"
+)
+
+// runInit implements the init command: it reads the Brella settings from
+// a captured attendees request in a HAR file and prints them as shell
+// exports, or writes them to a file to source.
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: bitcoinconf init --har FILE [flags]")
+		fs.PrintDefaults()
+	}
+	harPath := fs.String("har", "", "HAR file exported from Proxyman (or a browser) with an authorized Brella attendees request")
+	outPath := fs.String("out", "", `write the settings to this file (mode 0600) to source with ". FILE" instead of printing them`)
+
+	fs.Parse(args)
+	if *harPath == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*harPath)
+	if err != nil {
+		fatal("read har error", "err", err)
+	}
+	vars, err := config.EnvFromHAR(f)
+	f.Close()
+	if err != nil {
+		fatal("read har error", "path", *harPath, "err", err)
+	}
+
+	hasAuth := false
+	for _, v := range vars {
+		hasAuth = hasAuth || v.Name == "BITCONF_API_AUTH_TOKEN" || v.Name == "BITCONF_ACCESS_TOKEN" || v.Name == "BITCONF_SESSION_COOKIE"
+	}
+	if !hasAuth {
+		fmt.Fprintln(os.Stderr, "warning: the captured request carried no Brella credentials; capture one made while logged in")
+	}
+
+	if *outPath == "" {
+		if err := writeExports(os.Stdout, vars); err != nil {
+			fatal("write error", "err", err)
+		}
+		return
+	}
+	out, err := os.OpenFile(*outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		fatal("write error", "err", err)
+	}
+	if err := writeExports(out, vars); err != nil {
+		out.Close()
+		fatal("write error", "path", *outPath, "err", err)
+	}
+	if err := out.Close(); err != nil {
+		fatal("write error", "path", *outPath, "err", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d settings to %s; load them with: . %s\n", len(vars), *outPath, *outPath)
+}
+
+// writeExports writes vars as shell export statements.
+func writeExports(w io.Writer, vars []config.EnvVar) error {
+	for _, v := range vars {
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", v.Name, shellQuote(v.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bitcoinconferencescraper/internal/export"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// inputFlags holds the flags describing input files (--in, or the files
+// to diff).
+type inputFlags struct {
+	format     *string
+	csvColumns *string
+}
+
+// addInputFlags registers the input format flags on fs.
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	return &inputFlags{
+		format:     fs.String("input-format", "auto", "input file format: json (array, wrapper, or NDJSON), csv, or auto to pick csv for .csv files and json otherwise"),
+		csvColumns: fs.String("csv-columns", "", `with csv input, comma-separated header=field pairs mapping columns to profile fields, e.g. "Full Name=name,Organisation=company" ("-" ignores a column); CSV output headers and common names like Organization or Job Title match without one`),
+	}
+}
+
+// read reads the profiles in path, and the events when it's a JSON
+// wrapper (see readProfilesJSON).
+func (in *inputFlags) read(path string) ([]scraper.Profile, []scraper.Event, error) {
+	format := strings.ToLower(*in.format)
+	if format == "auto" {
+		format = "json"
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = "csv"
+		}
+	}
+
+	switch format {
+	case "json":
+		return readProfilesJSON(path)
+	case "csv":
+		cols, err := export.ParseCSVColumns(*in.csvColumns)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--csv-columns: %w", err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		profiles, err := export.ReadCSV(f, cols)
+		return profiles, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown --input-format %q (want auto, json, or csv)", *in.format)
+	}
+}
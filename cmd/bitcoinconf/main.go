@@ -2,107 +2,759 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math/rand/v2"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"golang.org/x/time/rate"
+
+	"bitcoinconferencescraper/internal/activehours"
+	"bitcoinconferencescraper/internal/breaker"
 	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/export"
 	"bitcoinconferencescraper/internal/linkedin"
 	"bitcoinconferencescraper/internal/scraper"
+	"bitcoinconferencescraper/internal/store"
 )
 
+// usage is printed for an unknown subcommand.
+const usage = `usage: bitcoinconf [scrape] [flags]   scrape Brella attendees, then enrich and write them
+       bitcoinconf enrich --in FILE [flags]   enrich existing profiles; needs only the search API config
+       bitcoinconf diff [flags] OLD NEW       report profiles added, removed, and changed between two outputs
+       bitcoinconf init --har FILE [flags]    print the Brella settings of a request captured in a HAR file
+
+Run "bitcoinconf <command> -h" for the flags of each command.
+`
+
 func main() {
+	args := os.Args[1:]
+
+	// Without a subcommand, flags go to scrape, which is what the CLI did
+	// before subcommands existed.
+	cmd := "scrape"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "scrape":
+		runScrape(args)
+	case "enrich":
+		runEnrich(args)
+	case "diff":
+		runDiff(args)
+	case "init":
+		runInit(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+}
+
+// runScrape implements the scrape command: it scrapes (or loads) profiles,
+// then validates, enriches and writes them.
+func runScrape(args []string) {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	common := addCommonFlags(fs, "ndjson is also streamed while scraping; to stdout only with nothing to do afterwards: no --db, --validate, --sheets-id, or enrichment")
+
 	var (
-		outputPath = flag.String("out", "profiles.json", "output file path (JSON)")
-		inputPath  = flag.String("in", "", "optional input file path (JSON) with existing profiles; if set, scraping is skipped")
-		pageLimit  = flag.Int("page-limit", 0, "maximum number of pages to scrape (0 = all)")
-		pageSize   = flag.Int("page-size", 50, "number of profiles per page when calling the API")
-		timeoutSec = flag.Int("timeout-sec", 30, "HTTP client timeout in seconds")
+		inputPath   = fs.String("in", "", "optional input file path (JSON, NDJSON, or CSV; see --input-format) with existing profiles; if set, scraping is skipped")
+		pageLimit   = fs.Int("page-limit", 0, "maximum number of pages to fetch in this run, counted from --start-page (0 = all)")
+		startPage   = fs.Int("start-page", 1, "first attendee list page to fetch; with --page-limit this scrapes a page range")
+		pageSize    = fs.Int("page-size", 50, "number of profiles per page when calling the API (at most 200; larger values are lowered with a warning)")
+		autoPage    = fs.Bool("auto-page-size", false, "halve --page-size (down to 10) and list the page again whenever listing fails with a timeout, 5xx, or too-large error after retries")
+		concurrency = fs.Int("concurrency", 1, "number of attendee detail requests in flight at once")
+		batchSize   = fs.Int("batch-size", 0, "fetch up to this many attendee details per request by filtering the attendee list by ID (undocumented by Brella; falls back to one at a time if unsupported); 0 or 1 fetches one at a time")
+		maxProfiles = fs.Int("max-profiles", 0, "stop after collecting this many profiles, even mid-page (0 = no cap)")
+		sample      = fs.Int("sample", 0, "scrape only this many randomly chosen attendees of each event (and of each other --roles list), listing only the pages that hold them, to try settings on a representative slice (0 = everyone)")
+		seed        = fs.Uint64("seed", 0, "seed for choosing the --sample, so a run can be repeated with the same attendees (0 = random; the seed used is logged)")
+		roles       = fs.String("roles", "attendees", "comma-separated lists to scrape per event: attendees, speakers, sponsors; speakers and sponsors come with full records, so they cost no detail requests")
+		search      = fs.String("search", "", `only list attendees matching this keyword, using Brella's own attendee search (e.g. "bitcoin core"); much cheaper than scraping everyone and filtering`)
+		since       = fs.String("since", "", "only keep attendees registered on or after this date (2025-06-01 or RFC 3339) and stop paging once older ones appear")
+
+		checkpointPath  = fs.String("checkpoint", "", "optional checkpoint file (JSON); progress is saved there and an existing checkpoint is resumed")
+		merge           = fs.Bool("merge", false, "with --in, scrape fresh profiles and merge them into the input by ID instead of skipping the scrape")
+		streamAll       = fs.Bool("stream", false, "for events too large to hold in memory: write each profile to the ndjson --out as it is fetched without keeping it, and list the next page while fetching the current one; nothing can be done with the profiles afterwards (enrichment, --db, --validate, --report, ...), and with --checkpoint or --append the output is added to, so a resumed run continues it")
+		listOnly        = fs.Bool("list-only", false, "only list attendees and write them as stubs with just their IDs, skipping the detail requests; with --checkpoint, a later run without it fetches their details")
+		dryRun          = fs.Bool("dry-run", false, "only walk the attendee list pages and report the count and estimated scrape time; no details are fetched and nothing is written")
+		maxRequests     = fs.Int("max-requests", 20000, "before scraping, estimate the Brella requests from each list's total and, if there are more than this, ask on the terminal whether to continue; without a terminal only a warning is logged (0 = don't check)")
+		assumeYes       = fs.Bool("yes", false, "continue past the --max-requests check without asking")
+		dbPath          = fs.String("db", "", "optional SQLite database; profiles are upserted there and the full table is enriched and written out")
+		retryOnError    = fs.Int("retry-on-error", 0, "with --checkpoint, restart a failed scrape from the checkpoint up to N times")
+		retryDelay      = fs.Duration("retry-delay", 30*time.Second, "wait before the first --retry-on-error restart; doubles with each restart")
+		checkpointEvery = fs.Int("checkpoint-every", 50, "number of profiles between checkpoint flushes (a checkpoint is also written after every page)")
+		continueOnError = fs.Bool("continue-on-error", false, "skip attendees whose details can't be fetched (after retries) instead of stopping; they are listed in --errors-out and the exit status is non-zero")
+		errorsOut       = fs.String("errors-out", "errors.json", "with --continue-on-error, JSON file listing each skipped attendee ID and why (rewritten every scrape)")
+
+		filterCompany  = fs.String("filter-company", "", "comma-separated, case-insensitive substrings; keep only profiles whose company contains one")
+		filterTitle    = fs.String("filter-title", "", "comma-separated, case-insensitive substrings; keep only profiles whose title contains one")
+		skipNonPersons = fs.Bool("skip-non-persons", false, "drop booth, sponsor, and staff accounts and other records that don't look like people")
+		perCompany     = fs.Int("profile-limit-per-company", 0, "keep at most this many profiles per company (case-insensitive), the first ones scraped or read, dropping the rest before enrichment (0 = no limit)")
+		location       = fs.String("location", "countries", "how the location column is filled: countries (company countries, else time zone), timezone (time zone, else countries), or separate (left blank; see the countries and time_zone columns)")
+
+		eventInfo     = fs.Bool("event-info", false, "fetch each event's name, dates, and location before scraping and write them with the profiles, as {\"events\": [...], \"profiles\": [...]} (json format only)")
+		progressEvery = fs.Int("progress-every", 100, "print scrape progress to stderr every N attendees (and at least every 10s); 0 disables")
+		activeHours   = fs.String("active-hours", "", "only send Brella requests during this daily window, such as 22:00-06:00, pausing the scrape outside it until the window reopens")
+		activeHoursTZ = fs.String("active-hours-tz", "", "time zone of --active-hours, such as Europe/Helsinki (default the first event's time zone, else local time)")
 	)
 
-	flag.Parse()
+	input := addInputFlags(fs)
+
+	fs.Parse(args)
+	logger := common.setup()
+	outputPath, format := common.outputPath, common.format
+
+	if *merge && *inputPath == "" {
+		fatal("flag error", "err", "--merge requires --in")
+	}
+	scrapeRoles, err := scraper.ParseRoles(*roles)
+	if err != nil {
+		fatal("flag error", "err", fmt.Errorf("--roles: %w", err))
+	}
+	locationStrategy, err := scraper.ParseLocationStrategy(*location)
+	if err != nil {
+		fatal("flag error", "err", fmt.Errorf("--location: %w", err))
+	}
+	var activeWindow *activehours.Window
+	if *activeHours != "" {
+		var loc *time.Location
+		if *activeHoursTZ != "" {
+			if loc, err = time.LoadLocation(*activeHoursTZ); err != nil {
+				fatal("flag error", "err", fmt.Errorf("--active-hours-tz: %w", err))
+			}
+		}
+		w, err := activehours.Parse(*activeHours, loc)
+		if err != nil {
+			fatal("flag error", "err", fmt.Errorf("--active-hours: %w", err))
+		}
+		activeWindow = &w
+	}
+	var sinceTime time.Time
+	if *since != "" {
+		var err error
+		if sinceTime, err = parseSince(*since); err != nil {
+			fatal("flag error", "err", fmt.Errorf("--since: %w", err))
+		}
+	}
+	if *eventInfo && *format != "json" {
+		fatal("flag error", "err", "--event-info needs --format json")
+	}
+	if *streamAll && (*inputPath != "" || *format != "ndjson") {
+		fatal("flag error", "err", "--stream needs --format ndjson and can't be used with --in")
+	}
+	if *sample > 0 && (*inputPath != "" || *checkpointPath != "") {
+		fatal("flag error", "err", "--sample can't be used with --in or --checkpoint")
+	}
+	if *retryOnError > 0 && *checkpointPath == "" {
+		fatal("flag error", "err", "--retry-on-error requires --checkpoint")
+	}
 
 	cfg, err := config.FromEnv()
 	if err != nil {
-		log.Fatalf("config error: %v", err)
+		fatal("config error", "err", err)
+	}
+	secrets.set(cfg.Secrets())
+	if !cfg.HasBrellaAuth() && (*inputPath == "" || *merge) {
+		logger.Warn("no Brella credentials configured; the API will likely reject requests",
+			"env", "BITCONF_API_AUTH_TOKEN, BITCONF_ACCESS_TOKEN/BITCONF_CLIENT/BITCONF_UID, or BITCONF_SESSION_COOKIE")
 	}
 
-	httpClient := config.NewHTTPClient(time.Duration(*timeoutSec) * time.Second)
+	httpClient := common.httpClient(cfg)
 
 	apiClient := scraper.NewClient(cfg.APIBaseURL, cfg.AuthToken, httpClient)
 	apiClient.AccessToken = cfg.AccessToken
 	apiClient.ClientID = cfg.ClientID
 	apiClient.UID = cfg.UID
 	apiClient.SessionCookie = cfg.SessionCookie
+	apiClient.Cookies = cfg.Cookies
+	apiClient.RefreshPath = cfg.RefreshPath
+	apiClient.RefreshToken = cfg.RefreshToken
+	apiClient.OnTokenRefresh = func(accessToken, clientID, uid string) {
+		secrets.add(accessToken, clientID, uid)
+	}
 	apiClient.BrellaMediaType = cfg.BrellaMediaType
+	apiClient.AcceptMediaType = cfg.AcceptMediaType
+	apiClient.UserAgent = cfg.UserAgent
+	apiClient.ExtraHeaders = cfg.ExtraHeaders
+	if err := scraper.CheckFieldMap(cfg.FieldMap); err != nil {
+		fatal("config error", "err", fmt.Errorf("BITCONF_FIELD_MAP: %w", err))
+	}
+	apiClient.FieldMap = cfg.FieldMap
+	apiClient.MaxRetries = cfg.MaxRetries
+	apiClient.RetryBudget = cfg.RetryBudget
+	apiClient.BaseRetryDelay = cfg.RetryBaseDelay
+	apiClient.RequestTimeout = cfg.RequestTimeout
+	apiClient.MaxResponseBytes = cfg.MaxResponseBytes
+	apiClient.Location = locationStrategy
+	apiClient.Breaker = &breaker.Breaker{Threshold: cfg.BreakerThreshold, Cooldown: cfg.BreakerCooldown}
+	apiClient.Logger = logger
+	if common.metrics != nil {
+		apiClient.Metrics = common.metrics
+	}
 
-	ctx := context.Background()
+	// One limiter shared by the Brella client and the LinkedIn matcher
+	// caps the whole run's request rate.
+	limiter := newRateLimiter(cfg)
+	apiClient.Limiter = limiter
+	matcher := common.newMatcher(common.searchClient(cfg), cfg, limiter)
+
+	ctx, stop := signalContext(*common.maxRuntime)
+	defer stop()
+
+	// Active hours come first so the media-type probe waits for the window
+	// too; only the event lookup for the window's time zone can't.
+	if activeWindow != nil && (*inputPath == "" || *merge) {
+		if *activeHoursTZ == "" {
+			activeWindow.Loc = eventLocation(ctx, apiClient, cfg.EventIDs[0])
+		}
+		logger.Info("limiting requests to active hours", "active_hours", activeWindow.String(), "time_zone", activeWindow.Loc.String())
+		apiClient.ActiveHours = activeWindow
+	}
+	if apiClient.BrellaMediaType == "" && (*inputPath == "" || *merge) {
+		mediaType, err := apiClient.DetectMediaType(ctx, cfg.EventIDs[0])
+		if err != nil {
+			mediaType = scraper.DefaultBrellaMediaType
+			logger.Warn("couldn't detect the Brella media type; set BITCONF_BRELLA_MEDIA_TYPE if requests fail", "err", err, "media_type", mediaType)
+		} else {
+			logger.Info("detected Brella media type", "media_type", mediaType)
+		}
+		apiClient.BrellaMediaType = mediaType
+	}
+
+	var db *store.Store
+	if *dbPath != "" {
+		db, err = store.Open(*dbPath)
+		if err != nil {
+			fatal("db error", "err", err)
+		}
+		defer db.Close()
+	}
 
 	var profiles []scraper.Profile
 
+	// Count what the --filter-* flags drop so the summary can report it.
+	filteredOut := 0
+	var keep func(scraper.Profile) bool
+	if match := keywordFilter(splitKeywords(*filterCompany), splitKeywords(*filterTitle)); match != nil {
+		keep = func(p scraper.Profile) bool {
+			if match(p) {
+				return true
+			}
+			filteredOut++
+			return false
+		}
+	}
+
+	var fetchErrs []scraper.FetchError
+	// streamed is set when profiles went to the output as they were
+	// scraped, which makes those the final output.
+	streamed := false
+	// written counts the profiles streamed, which --stream doesn't keep.
+	written := 0
+
+	var existing []scraper.Profile
 	if *inputPath != "" {
-		log.Printf("loading existing profiles from %s (skipping Brella scraping)", *inputPath)
-		profiles, err = readProfilesJSON(*inputPath)
+		if *merge {
+			logger.Info("loading existing profiles to merge with a fresh scrape", "path", *inputPath)
+		} else {
+			logger.Info("loading existing profiles (skipping Brella scraping)", "path", *inputPath)
+		}
+		existing, common.events, err = input.read(*inputPath)
 		if err != nil {
-			log.Fatalf("read input error: %v", err)
+			fatal("read input error", "err", err)
+		}
+	}
+
+	if *inputPath != "" && !*merge {
+		profiles = existing
+		if *skipNonPersons {
+			profiles = filterProfiles(profiles, scraper.IsPerson)
+		}
+		if !sinceTime.IsZero() {
+			profiles = filterProfiles(profiles, func(p scraper.Profile) bool {
+				return p.RegisteredAt.IsZero() || !p.RegisteredAt.Before(sinceTime)
+			})
+		}
+		if keep != nil {
+			profiles = filterProfiles(profiles, keep)
 		}
 	} else {
 		profileScraper := scraper.Scraper{
 			Client:               apiClient,
 			PageSize:             *pageSize,
-			EventID:              cfg.EventID,
+			AutoPageSize:         *autoPage,
+			StartPage:            *startPage,
+			Search:               *search,
+			EventIDs:             cfg.EventIDs,
+			Roles:                scrapeRoles,
 			DelayBetweenRequests: cfg.RequestDelay,
+			DelayJitter:          cfg.DelayJitter,
+			Concurrency:          *concurrency,
+			BatchSize:            *batchSize,
+			ListOnly:             *listOnly,
+			Stream:               *streamAll,
+			MaxProfiles:          *maxProfiles,
+			Sample:               *sample,
+			Seed:                 *seed,
+			Since:                sinceTime,
+			Metrics:              apiClient.Metrics,
+			SkipNonPersons:       *skipNonPersons,
+			CheckpointPath:       *checkpointPath,
+			CheckpointEvery:      *checkpointEvery,
+			Restarts:             *retryOnError,
+			RestartDelay:         *retryDelay,
+			ContinueOnError:      *continueOnError,
+			Filter:               keep,
+			Logger:               logger,
+		}
+		profileScraper.OnFetchError = func(e scraper.FetchError) {
+			fetchErrs = append(fetchErrs, e)
+		}
+		if *sample > 0 {
+			if profileScraper.Seed == 0 {
+				profileScraper.Seed = rand.Uint64()
+			}
+			logger.Info("scraping a random sample", "sample", *sample, "seed", profileScraper.Seed)
+		}
+		if *progressEvery > 0 {
+			profileScraper.ProgressFunc = newProgressReporter(os.Stderr, *progressEvery, 10*time.Second).Report
 		}
 
-		profiles, err = profileScraper.ScrapeAllProfiles(ctx, *pageLimit)
+		if *dryRun {
+			count, err := profileScraper.CountAttendees(ctx, *pageLimit)
+			if err != nil {
+				fatal("dry run error", "err", err)
+			}
+			interval := cfg.RequestDelay
+			if cfg.RateLimit > 0 {
+				if d := time.Duration(float64(time.Second) / cfg.RateLimit); d > interval {
+					interval = d
+				}
+			}
+			estimate := time.Duration(count) * interval
+			fmt.Printf("found %d attendees; estimated detail fetch time %s at %s between requests\n", count, estimate, interval)
+			return
+		}
+
+		if *maxRequests > 0 && *sample <= 0 {
+			// A failed probe is left for the scrape itself to report.
+			est, err := profileScraper.EstimateRequests(ctx, *pageLimit)
+			if err != nil {
+				logger.Warn("couldn't estimate the scrape's requests", "err", err)
+			} else {
+				if len(est.Unknown) > 0 {
+					logger.Warn("lists report no total; their requests aren't estimated", "lists", strings.Join(est.Unknown, ", "))
+				}
+				logger.Info("estimated scrape requests", "requests", est.Requests(), "list_requests", est.ListRequests, "detail_requests", est.DetailRequests, "entries", est.Entries)
+				if est.Requests() > *maxRequests {
+					switch {
+					case *assumeYes:
+						logger.Warn("scrape exceeds --max-requests; continuing because of --yes", "requests", est.Requests(), "max_requests", *maxRequests)
+					case !isTerminal(os.Stdin):
+						// Scheduled runs have nobody to ask; don't fail them.
+						logger.Warn("scrape exceeds --max-requests; continuing without a terminal to confirm on", "requests", est.Requests(), "max_requests", *maxRequests,
+							"hint", "raise --page-size or set --page-limit to make fewer requests")
+					case !confirmRequests(est, *maxRequests, os.Stdin, os.Stderr):
+						fatal("scrape not confirmed", "requests", est.Requests(), "max_requests", *maxRequests,
+							"hint", "raise --page-size or --max-requests, set --page-limit, or pass --yes")
+					}
+				}
+			}
+		}
+
+		if *eventInfo {
+			common.events = mergeEvents(common.events, fetchEvents(ctx, apiClient, cfg.EventIDs))
+		}
+
+		// For NDJSON, stream profiles to the output as they arrive so a
+		// killed run still leaves everything fetched so far on disk. The
+		// file is rewritten in full once enrichment has finished. Merging
+		// skips this so the output (often the --in file itself) is never
+		// truncated to just the fresh profiles, and so does --append,
+		// which must keep the file.
+		//
+		// Stdout can't be rewritten, so what is streamed there is final.
+		// It is streamed (one write per profile, for tools like jq
+		// reading the pipe) only when nothing would change the profiles
+		// after the scrape; otherwise it is written once at the end.
+		// With --stream the profiles aren't kept, so what is streamed is
+		// final wherever it goes.
+		nothingAfter := db == nil && !*common.validate && *common.sheetsID == "" && !common.enriches(matcher) && *perCompany <= 0 && *common.dedupBy == "id" && !*common.review
+		if *streamAll && (*merge || !nothingAfter || *common.report != "") {
+			fatal("flag error", "err", "--stream writes profiles as they are fetched, so it can't be combined with --merge, --db, --validate, --sheets-id, enrichment (pass --linkedin=false), --profile-limit-per-company, --dedup-by name, --review, or --report")
+		}
+		var stream io.WriteCloser
+		switch {
+		case *format != "ndjson" || *merge:
+			// Nothing to stream.
+		case *outputPath == stdoutPath:
+			streamed = nothingAfter
+			if streamed {
+				// Have writes to a closed pipe fail with EPIPE instead of
+				// the process being killed, so the scrape can stop cleanly.
+				signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+				stream, _ = export.CreateStream(stdoutPath)
+			}
+		case *streamAll:
+			streamed = true
+			open := export.CreateStream
+			if *checkpointPath != "" || *common.appendOut {
+				open = export.AppendStream
+			}
+			stream, err = open(*outputPath)
+			if err != nil {
+				fatal("open output error", "err", err)
+			}
+		case !*common.appendOut:
+			stream, err = export.CreateStream(*outputPath)
+			if err != nil {
+				fatal("open output error", "err", err)
+			}
+		}
+		if stream != nil {
+			write := export.NewNDJSONWriter(stream, common.selected).Write
+			profileScraper.OnProfile = func(p scraper.Profile) error {
+				err := write(p)
+				if errors.Is(err, syscall.EPIPE) {
+					// Nobody is reading any more; stop everything.
+					stop()
+				}
+				if err == nil {
+					written++
+				}
+				return err
+			}
+		}
+
+		if *checkpointPath != "" {
+			profiles, err = profileScraper.Resume(ctx, *pageLimit)
+		} else {
+			profiles, err = profileScraper.ScrapeAllProfiles(ctx, *pageLimit)
+		}
+		if stream != nil {
+			stream.Close()
+		}
+		if *continueOnError {
+			if writeErr := writeFetchErrors(*errorsOut, fetchErrs); writeErr != nil {
+				logger.Error("write error report failed", "path", *errorsOut, "err", writeErr)
+			}
+		}
+		if *merge {
+			logger.Info("merging scraped profiles into existing", "scraped", len(profiles), "existing", len(existing))
+			profiles = scraper.MergeProfiles(existing, profiles)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, syscall.EPIPE):
+				logger.Warn("stdout was closed by its reader; stopped scraping", "profiles", len(profiles))
+				os.Exit(0)
+			case ctx.Err() != nil:
+				logger.Warn("scrape interrupted", "err", err)
+			case errors.Is(err, scraper.ErrUnauthorized):
+				logger.Error("scrape error: Brella rejected the credentials; they may have expired", "err", err,
+					"env", "BITCONF_API_AUTH_TOKEN, BITCONF_ACCESS_TOKEN/BITCONF_CLIENT/BITCONF_UID, or BITCONF_SESSION_COOKIE")
+			case errors.Is(err, scraper.ErrRateLimited):
+				logger.Error("scrape error: still rate limited after retries; raise BITCONF_REQUEST_DELAY_MS or lower BITCONF_RATE_LIMIT_RPS", "err", err)
+			default:
+				logger.Error("scrape error", "err", err)
+			}
+			if streamed {
+				// Everything collected is already in the output.
+				os.Exit(1)
+			}
+			saveToDB(db, profiles)
+			logger.Warn("writing partial results after error", "profiles", len(profiles), "path", *outputPath)
+			if writeErr := common.writeOutput(profiles); writeErr != nil {
+				fatal("write output error after scrape error", "err", writeErr)
+			}
+			os.Exit(1)
+		}
+	}
+
+	if db != nil {
+		saveToDB(db, profiles)
+		profiles, err = db.Profiles(ctx)
 		if err != nil {
-			log.Fatalf("scrape error: %v", err)
+			fatal("db error", "err", err)
+		}
+		logger.Info("loaded stored profiles for enrichment", "profiles", len(profiles), "db", *dbPath)
+	}
+
+	var drops []companyDrop
+	profiles, drops = limitPerCompany(profiles, *perCompany)
+	capped := 0
+	for _, d := range drops {
+		logger.Info("dropped profiles over per-company limit", "company", d.Company, "kept", *perCompany, "dropped", d.Dropped)
+		capped += d.Dropped
+	}
+
+	if streamed {
+		if err := common.printReport(profiles); err != nil {
+			fatal("report error", "err", err)
 		}
+	} else {
+		profiles = common.finish(ctx, matcher, db, profiles)
 	}
 
-	linkedinMatcher := linkedin.NewMatcher(httpClient, cfg)
-	profiles, err = linkedinMatcher.EnrichProfiles(ctx, profiles)
+	count := len(profiles)
+	if *streamAll {
+		count = written
+	}
+	if keep != nil {
+		common.summary("wrote %d profiles to %s (%d filtered out)", count, outputName(*outputPath), filteredOut)
+	} else {
+		common.summary("wrote %d profiles to %s", count, outputName(*outputPath))
+	}
+	if capped > 0 {
+		common.summary("dropped %d profiles from %d companies over --profile-limit-per-company %d", capped, len(drops), *perCompany)
+	}
+	if len(fetchErrs) > 0 {
+		common.summary("skipped %d attendees whose details couldn't be fetched; see %s", len(fetchErrs), *errorsOut)
+		os.Exit(1)
+	}
+}
+
+// finish is the tail shared by every command: it merges profiles with
+// --dedup-by name, validates them if --validate is set, enriches them with
+// m as --linkedin and --twitter ask, checks their LinkedIn URLs with
+// --verify-urls, asks which LinkedIn candidates are right with --review,
+// saves them to db (if not nil), writes the output, and prints --report.
+// On a validation or search failure it writes what it has and exits
+// non-zero; otherwise it returns the written profiles.
+func (c *commonFlags) finish(ctx context.Context, m *linkedin.Matcher, db *store.Store, profiles []scraper.Profile) []scraper.Profile {
+	logger := slog.Default()
+
+	if *c.dedupBy == "name" {
+		before := len(profiles)
+		profiles = scraper.DedupeByName(profiles)
+		logger.Info("deduplicated profiles by name and company", "merged", before-len(profiles), "profiles", len(profiles))
+	}
+
+	if *c.validate {
+		errs := scraper.ValidateProfiles(profiles)
+		for _, verr := range errs {
+			logger.Warn("validation", "problem", verr)
+		}
+		if scraper.HasHardErrors(errs) {
+			logger.Error("validation failed; skipping enrichment", "problems", len(errs))
+			if writeErr := c.writeOutput(profiles); writeErr != nil {
+				fatal("write output error after validation failure", "err", writeErr)
+			}
+			os.Exit(1)
+		}
+		logger.Info("validation passed", "profiles", len(profiles), "warnings", len(errs))
+	}
+
+	var err error
+	if *c.enrichLinkedIn {
+		var stats linkedin.EnrichmentStats
+		profiles, stats, err = m.EnrichProfiles(ctx, profiles)
+		saveToDB(db, profiles)
+		if m.Enabled() {
+			c.summary("linkedin: %s", stats)
+		}
+	}
+	if err == nil && *c.enrichTwitter {
+		var stats linkedin.TwitterStats
+		profiles, stats, err = m.EnrichTwitter(ctx, profiles)
+		saveToDB(db, profiles)
+		if m.Enabled() {
+			c.summary("twitter: %s", stats)
+		}
+	}
+	if err == nil && *c.verifyURLs {
+		var stats linkedin.VerifyStats
+		profiles, stats, err = m.VerifyURLs(ctx, profiles)
+		saveToDB(db, profiles)
+		c.summary("verify: %s", stats)
+	}
 	if err != nil {
-		log.Printf("linkedin matching error: %v", err)
-		log.Printf("writing partial results to %s after error", *outputPath)
-		if writeErr := writeProfilesJSON(*outputPath, profiles); writeErr != nil {
-			log.Fatalf("write output error after linkedin error: %v", writeErr)
+		if ctx.Err() != nil {
+			logger.Warn("enrichment interrupted", "err", err)
+		} else if errors.Is(err, linkedin.ErrSearchQuotaExceeded) {
+			logger.Error("search API quota exhausted; rerun tomorrow (or once the quota resets) to search the remaining profiles", "err", err)
+		} else if errors.Is(err, linkedin.ErrNoResultsStreak) {
+			logger.Error("linkedin enrichment stopped by --stop-after-no-results; check the search API before rerunning to search the remaining profiles", "err", err)
+		} else {
+			logger.Error("enrichment error", "err", err)
+		}
+		logger.Warn("writing partial results after error", "profiles", len(profiles), "path", *c.outputPath)
+		if writeErr := c.writeOutput(profiles); writeErr != nil {
+			fatal("write output error after enrichment error", "err", writeErr)
 		}
 		os.Exit(1)
 	}
 
-	if err := writeProfilesJSON(*outputPath, profiles); err != nil {
-		log.Fatalf("write output error: %v", err)
+	if *c.review {
+		decisions, err := linkedin.LoadReviewDecisions(*c.reviewDecisions)
+		if err != nil {
+			fatal("review decisions error", "err", err)
+		}
+		profiles = reviewLinkedIn(profiles, decisions, os.Stdin, os.Stderr)
+		if err := decisions.Save(); err != nil {
+			logger.Error("save review decisions failed", "path", *c.reviewDecisions, "err", err)
+		}
+		saveToDB(db, profiles)
 	}
 
-	fmt.Printf("wrote %d profiles to %s\n", len(profiles), *outputPath)
+	if err := c.writeOutput(profiles); err != nil {
+		fatal("write output error", "err", err)
+	}
+	if *c.sheetsID != "" {
+		if err := c.writeSheets(ctx, profiles); err != nil {
+			fatal("write sheets error", "err", err)
+		}
+	}
+	if err := c.printReport(profiles); err != nil {
+		fatal("report error", "err", err)
+	}
+	return profiles
 }
 
-func writeProfilesJSON(path string, profiles []scraper.Profile) error {
-	f, err := os.Create(path)
+// parseSince parses a --since value given as a date (midnight UTC) or a
+// full RFC 3339 time.
+func parseSince(v string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
 	if err != nil {
-		return err
+		return time.Time{}, fmt.Errorf("%q is neither a date like 2025-06-01 nor an RFC 3339 time", v)
+	}
+	return t, nil
+}
+
+// newRateLimiter returns the limiter shared by every outbound request of a
+// run, or nil when BITCONF_RATE_LIMIT_RPS is unset.
+func newRateLimiter(cfg config.Config) *rate.Limiter {
+	if cfg.RateLimit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(cfg.RateLimit), 1)
+}
+
+// errMaxRuntime is the cancellation cause once --max-runtime has passed.
+var errMaxRuntime = errors.New("max runtime reached")
+
+// signalContext returns a context cancelled on Ctrl-C or SIGTERM (what
+// container runtimes send to stop a process), so in-flight waits and
+// requests stop promptly and whatever was collected is still written out,
+// with the checkpoint saved for a resumed run. Once the context is
+// cancelled the signals get their default behavior back, so a second one
+// kills the process straight away.
+//
+// With maxRuntime > 0, the context is also cancelled, with errMaxRuntime
+// as its cause, once that much time has passed. This is a timer rather
+// than a context deadline because rate.Limiter refuses up front any wait
+// that would end past a deadline, with an error that doesn't read as the
+// run being stopped.
+func signalContext(maxRuntime time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if maxRuntime <= 0 {
+		return ctx, stop
 	}
-	defer f.Close()
 
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	return enc.Encode(profiles)
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(maxRuntime, func() {
+		slog.Warn("--max-runtime reached; stopping and writing what has been collected", "max_runtime", maxRuntime)
+		cancel(errMaxRuntime)
+	})
+	return ctx, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+		stop()
+	}
+}
+
+// fetchEvents fetches the metadata of each event. An event that can't be
+// fetched is logged and listed with its ID alone, so the scrape goes on.
+func fetchEvents(ctx context.Context, client *scraper.Client, eventIDs []string) []scraper.Event {
+	events := make([]scraper.Event, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		event, err := client.GetEvent(ctx, id)
+		if err != nil {
+			slog.Warn("fetching event info failed; recording the event ID only", "event_id", id, "err", err)
+			event = scraper.Event{ID: id}
+		} else {
+			slog.Info("event", "event_id", id, "name", event.Name, "starts_at", event.StartsAt, "ends_at", event.EndsAt, "location", event.Location)
+		}
+		events = append(events, event)
+	}
+	return events
 }
 
-func readProfilesJSON(path string) ([]scraper.Profile, error) {
-	f, err := os.Open(path)
+// eventLocation returns the time zone of eventID as Brella gives it, or
+// local time if it can't be had.
+func eventLocation(ctx context.Context, client *scraper.Client, eventID string) *time.Location {
+	event, err := client.GetEvent(ctx, eventID)
 	if err != nil {
-		return nil, err
+		slog.Warn("fetching event time zone failed; using local time", "event_id", eventID, "err", err)
+		return time.Local
 	}
-	defer f.Close()
+	if event.TimeZone == "" {
+		slog.Warn("event has no time zone; using local time", "event_id", eventID)
+		return time.Local
+	}
+	loc, err := time.LoadLocation(event.TimeZone)
+	if err != nil {
+		slog.Warn("event time zone unknown; using local time", "event_id", eventID, "time_zone", event.TimeZone, "err", err)
+		return time.Local
+	}
+	return loc
+}
 
-	var profiles []scraper.Profile
-	if err := json.NewDecoder(f).Decode(&profiles); err != nil {
-		return nil, err
+// saveToDB upserts profiles into db, if one is configured. It uses its own
+// context so results are still persisted after the run was cancelled.
+func saveToDB(db *store.Store, profiles []scraper.Profile) {
+	if db == nil {
+		return
 	}
-	return profiles, nil
+
+	inserted, updated, err := db.Upsert(context.Background(), profiles)
+	if err != nil {
+		slog.Error("db upsert error", "err", err)
+		return
+	}
+	slog.Info("saved profiles to db", "new", inserted, "updated", updated)
+}
+
+// newLogger builds the CLI's stderr logger from the --log-level,
+// --log-format and --quiet flags.
+func newLogger(level, format string, quiet bool) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q (want debug, info, warn, or error)", level)
+	}
+	if quiet && lvl < slog.LevelWarn {
+		lvl = slog.LevelWarn
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: secrets.replaceAttr}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+}
+
+// fatal logs msg at error level and exits with status 1.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+	"bitcoinconferencescraper/internal/export"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// stdoutPath is the --out value that writes to standard output.
+const stdoutPath = export.Stdout
+
+// outputName describes path in messages.
+func outputName(path string) string {
+	if path == stdoutPath {
+		return "stdout"
+	}
+	return path
+}
+
+// mergeExisting returns profiles merged by ID (see scraper.MergeProfiles)
+// into the existing JSON output at path, which may not exist yet, along
+// with the events it already lists.
+func mergeExisting(path string, profiles []scraper.Profile) ([]scraper.Profile, []scraper.Event, error) {
+	existing, events, err := readProfilesJSON(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("reading existing output: %w", err)
+	}
+	return scraper.MergeProfiles(existing, profiles), events, nil
+}
+
+// appendProfilesNDJSON appends the profiles whose IDs don't appear in the
+// NDJSON file at path yet. Only the id of each existing line is decoded.
+func appendProfilesNDJSON(path string, fields export.Fields, profiles []scraper.Profile) error {
+	seen, err := readNDJSONIDs(path)
+	if err != nil {
+		return fmt.Errorf("reading existing output: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	w := export.NewNDJSONWriter(f, fields)
+	for _, p := range profiles {
+		if p.ID != "" && seen[p.ID] {
+			continue
+		}
+		if err := w.Write(p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// readNDJSONIDs returns the profile IDs in the NDJSON file at path, or an
+// empty set if the file doesn't exist.
+func readNDJSONIDs(path string) (map[string]bool, error) {
+	seen := make(map[string]bool)
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var line struct {
+			ID string `json:"id"`
+		}
+		if err := dec.Decode(&line); err == io.EOF {
+			return seen, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("decoding NDJSON profile %d: %w", len(seen)+1, err)
+		}
+		seen[line.ID] = true
+	}
+}
+
+// fetchErrorRecord is one entry of the --errors-out report.
+type fetchErrorRecord struct {
+	EventID    string `json:"event_id"`
+	AttendeeID string `json:"attendee_id"`
+	Error      string `json:"error"`
+}
+
+// writeFetchErrors writes the attendees skipped under --continue-on-error
+// to path as a JSON array, which is empty if none were.
+func writeFetchErrors(path string, errs []scraper.FetchError) error {
+	records := make([]fetchErrorRecord, 0, len(errs))
+	for _, e := range errs {
+		records = append(records, fetchErrorRecord{EventID: e.EventID, AttendeeID: e.AttendeeID, Error: e.Err.Error()})
+	}
+
+	f, err := atomicfile.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// readProfilesJSON reads profiles from a JSON array, a JSON object
+// wrapping them with event metadata (see export.Wrapper), or
+// newline-delimited JSON, detected from the first value. The events are
+// nil unless the file is a wrapper.
+func readProfilesJSON(path string) ([]scraper.Profile, []scraper.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	first, err := peekNonSpace(r)
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec := json.NewDecoder(r)
+
+	var profiles []scraper.Profile
+	if first == '[' {
+		if err := dec.Decode(&profiles); err != nil {
+			return nil, nil, err
+		}
+		return profiles, nil, nil
+	}
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("decoding NDJSON profile %d: %w", len(profiles)+1, err)
+		}
+
+		if len(profiles) == 0 {
+			// Every profile has a name key; the wrapper doesn't.
+			var probe struct {
+				Name     *string         `json:"name"`
+				Profiles json.RawMessage `json:"profiles"`
+			}
+			if json.Unmarshal(raw, &probe) == nil && probe.Name == nil && probe.Profiles != nil {
+				var w struct {
+					Events   []scraper.Event   `json:"events"`
+					Profiles []scraper.Profile `json:"profiles"`
+				}
+				if err := json.Unmarshal(raw, &w); err != nil {
+					return nil, nil, err
+				}
+				if w.Events == nil {
+					w.Events = []scraper.Event{}
+				}
+				return w.Profiles, w.Events, nil
+			}
+		}
+
+		var p scraper.Profile
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, nil, fmt.Errorf("decoding NDJSON profile %d: %w", len(profiles)+1, err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil, nil
+}
+
+// mergeEvents combines two event lists by ID, keeping the order of old
+// with new IDs appended; an event in fresh replaces the old one. It
+// returns nil only if both are nil.
+func mergeEvents(old, fresh []scraper.Event) []scraper.Event {
+	if old == nil && fresh == nil {
+		return nil
+	}
+	out := append([]scraper.Event{}, old...)
+	for _, e := range fresh {
+		i := slices.IndexFunc(out, func(o scraper.Event) bool { return o.ID == e.ID })
+		if i >= 0 {
+			out[i] = e
+		} else {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// peekNonSpace skips leading whitespace in r and returns the next byte
+// without consuming it.
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
+}
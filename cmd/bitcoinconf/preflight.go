@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// confirmRequests asks whether a scrape estimated to make est's requests,
+// more than limit, may go ahead, writing the prompt to out and reading the
+// answer from in. Only y or yes confirms; anything else, or in running
+// out, doesn't.
+func confirmRequests(est scraper.RequestEstimate, limit int, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "this scrape is estimated to make %d requests (%d list pages, %d detail requests), more than --max-requests %d; continue? [y/N] ",
+		est.Requests(), est.ListRequests, est.DetailRequests, limit)
+	sc := bufio.NewScanner(in)
+	if !sc.Scan() {
+		fmt.Fprintln(out)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(sc.Text())) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+func TestConfirmRequests(t *testing.T) {
+	est := scraper.RequestEstimate{ListRequests: 30000, DetailRequests: 30000}
+	for answer, want := range map[string]bool{
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"\n":    false,
+		"":      false,
+		"sure":  false,
+	} {
+		if got := confirmRequests(est, 20000, strings.NewReader(answer), io.Discard); got != want {
+			t.Errorf("answer %q confirmed = %v, want %v", answer, got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressReporter prints scrape progress lines, with a percentage and ETA
+// when the total is known, every `every` attendees or every `interval`,
+// whichever comes first. Its Report method fits scraper.Scraper.ProgressFunc.
+type progressReporter struct {
+	w        io.Writer
+	every    int
+	interval time.Duration
+
+	start      time.Time
+	startDone  int
+	lastDone   int
+	lastReport time.Time
+}
+
+func newProgressReporter(w io.Writer, every int, interval time.Duration) *progressReporter {
+	return &progressReporter{w: w, every: every, interval: interval}
+}
+
+// Report records that done of total attendees have been processed. A total
+// of 0 means the total is unknown, and only the count is printed.
+func (r *progressReporter) Report(done, total int) {
+	now := time.Now()
+	if r.start.IsZero() {
+		// Attendees restored from a checkpoint don't count toward the
+		// rate used for the ETA.
+		r.start = now
+		r.startDone = done - 1
+		r.lastReport = now
+	}
+
+	due := (r.every > 0 && done-r.lastDone >= r.every) ||
+		(r.interval > 0 && now.Sub(r.lastReport) >= r.interval) ||
+		(total > 0 && done >= total)
+	if !due {
+		return
+	}
+	r.lastDone = done
+	r.lastReport = now
+
+	if total <= 0 {
+		fmt.Fprintf(r.w, "progress: %d attendees fetched\n", done)
+		return
+	}
+
+	pct := float64(done) / float64(total) * 100
+	eta := "unknown"
+	if fetched := done - r.startDone; fetched > 0 && done < total {
+		perItem := now.Sub(r.start) / time.Duration(fetched)
+		eta = (perItem * time.Duration(total-done)).Round(time.Second).String()
+	} else if done >= total {
+		eta = "0s"
+	}
+	fmt.Fprintf(r.w, "progress: %d/%d (%.1f%%), ETA %s\n", done, total, pct, eta)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"bitcoinconferencescraper/internal/config"
+)
+
+// secrets masks the configured credentials wherever they turn up in log
+// output, such as in a search request URL quoted by an error.
+var secrets redactor
+
+// redactor replaces secret values in log attributes with config.Mask. The
+// logger is set up before the config is loaded, so it masks nothing until
+// set is called.
+type redactor struct {
+	replacer atomic.Pointer[strings.Replacer]
+
+	mu     sync.Mutex
+	values []string
+}
+
+// minSecretLen is the shortest value masked; masking shorter ones would
+// mangle unrelated text without hiding anything worth guessing.
+const minSecretLen = 4
+
+// set makes r mask values. Longer values go first, so a cookie header
+// containing a session cookie is masked as a whole.
+func (r *redactor) set(values []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = slices.Clone(values)
+	r.build()
+}
+
+// add makes r mask values as well as the ones it already masks, such as
+// tokens obtained by a refresh.
+func (r *redactor) add(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, values...)
+	r.build()
+}
+
+// build stores the replacer for r.values. r.mu must be held.
+func (r *redactor) build() {
+	values := slices.Clone(r.values)
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+
+	var pairs []string
+	for _, v := range values {
+		if len(v) >= minSecretLen {
+			pairs = append(pairs, v, config.Mask(v))
+		}
+	}
+	if len(pairs) == 0 {
+		r.replacer.Store(nil)
+		return
+	}
+	r.replacer.Store(strings.NewReplacer(pairs...))
+}
+
+// replaceAttr is a slog.HandlerOptions.ReplaceAttr masking secrets in
+// string and error values, and in the message.
+func (r *redactor) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	rep := r.replacer.Load()
+	if rep == nil {
+		return a
+	}
+
+	var s string
+	switch a.Value.Kind() {
+	case slog.KindString:
+		s = a.Value.String()
+	case slog.KindAny:
+		err, ok := a.Value.Any().(error)
+		if !ok {
+			return a
+		}
+		s = err.Error()
+	default:
+		return a
+	}
+	if masked := rep.Replace(s); masked != s {
+		a.Value = slog.StringValue(masked)
+	}
+	return a
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestRedactorAdd(t *testing.T) {
+	var r redactor
+	r.set([]string{"configured-secret"})
+	r.add("refreshed-token-0001", "abc", "")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"token configured-secret", "token ***cret"},
+		{"now refreshed-token-0001 is used", "now ***0001 is used"},
+		{"abc is too short to mask", "abc is too short to mask"},
+	}
+	for _, tt := range tests {
+		if got := r.replaceAttr(nil, slog.String("k", tt.in)).Value.String(); got != tt.want {
+			t.Errorf("masked %q as %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := r.replaceAttr(nil, slog.Any("err", errors.New("bad refreshed-token-0001"))).Value.String(); got != "bad ***0001" {
+		t.Errorf("masked error as %q, want %q", got, "bad ***0001")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// reportKinds are the --report values, in the order they are printed.
+var reportKinds = []string{"companies", "locations", "countries"}
+
+// parseReports parses a comma-separated --report value into the kinds to
+// print, in reportKinds order. An empty value selects none.
+func parseReports(v string) ([]string, error) {
+	want := make(map[string]bool)
+	for _, kind := range strings.Split(v, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "" {
+			continue
+		}
+		if grouping(scraper.Summary{}, kind) == nil {
+			return nil, fmt.Errorf("unknown report %q (want %s)", kind, strings.Join(reportKinds, ", "))
+		}
+		want[kind] = true
+	}
+
+	var kinds []string
+	for _, kind := range reportKinds {
+		if want[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// grouping returns the Grouping of s that a report kind prints, or nil for
+// an unknown kind.
+func grouping(s scraper.Summary, kind string) *scraper.Grouping {
+	switch kind {
+	case "companies":
+		return &s.Companies
+	case "locations":
+		return &s.Locations
+	case "countries":
+		return &s.Countries
+	default:
+		return nil
+	}
+}
+
+// printReport prints the --report aggregates of profiles where summary lines
+// go, as tables or, with --report-format json, as one JSON object.
+func (c *commonFlags) printReport(profiles []scraper.Profile) error {
+	if len(c.reports) == 0 {
+		return nil
+	}
+
+	w := io.Writer(os.Stdout)
+	if *c.outputPath == stdoutPath {
+		w = os.Stderr
+	}
+
+	s := scraper.Summarize(profiles)
+	if *c.reportFormat == "json" {
+		return writeReportJSON(w, s, c.reports, *c.reportTop)
+	}
+	return writeReportTables(w, s, c.reports, *c.reportTop)
+}
+
+// writeReportTables writes one table per report kind, each listing at most
+// top groups (all of them if top is 0).
+func writeReportTables(w io.Writer, s scraper.Summary, kinds []string, top int) error {
+	for i, kind := range kinds {
+		g := grouping(s, kind)
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s: %d distinct across %d profiles\n", kind, len(g.Groups), s.Total)
+
+		groups := g.Groups
+		if top > 0 && len(groups) > top {
+			groups = groups[:top]
+		}
+		width := len(strconv.Itoa(max(g.Without, 1)))
+		if len(groups) > 0 {
+			width = max(width, len(strconv.Itoa(groups[0].Count)))
+		}
+		for _, group := range groups {
+			fmt.Fprintf(w, "  %*d  %s\n", width, group.Count, group.Value)
+		}
+		if rest := len(g.Groups) - len(groups); rest > 0 {
+			fmt.Fprintf(w, "  %*s  (%d more)\n", width, "", rest)
+		}
+		if g.Without > 0 {
+			fmt.Fprintf(w, "  %*d  (none)\n", width, g.Without)
+		}
+	}
+	return nil
+}
+
+// writeReportJSON writes the total and the requested groupings, each cut to
+// top groups, as one indented JSON object keyed by report kind.
+func writeReportJSON(w io.Writer, s scraper.Summary, kinds []string, top int) error {
+	out := map[string]any{"total": s.Total}
+	for _, kind := range kinds {
+		g := *grouping(s, kind)
+		if top > 0 && len(g.Groups) > top {
+			g.Groups = g.Groups[:top]
+		}
+		out[kind] = g
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
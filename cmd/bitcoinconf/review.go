@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"bitcoinconferencescraper/internal/linkedin"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// reviewLinkedIn asks, for each profile with several close LinkedIn
+// candidates (see linkedin.CloseCandidates), which one is the person's,
+// reading answers from in and writing prompts to out. Picks made on earlier
+// runs are applied from decisions without asking, and new ones are
+// recorded there. Answering q, or in running out, ends the review with the
+// remaining profiles as they are.
+func reviewLinkedIn(profiles []scraper.Profile, decisions *linkedin.ReviewDecisions, in io.Reader, out io.Writer) []scraper.Profile {
+	var pending []int
+	for i, p := range profiles {
+		if url, ok := decisions.Get(p.ID); ok && p.ID != "" {
+			if url != "" {
+				profiles[i] = linkedin.ChooseLinkedIn(p, url)
+			}
+			continue
+		}
+		if linkedin.CloseCandidates(p) != nil {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return profiles
+	}
+
+	sc := bufio.NewScanner(in)
+	for n, i := range pending {
+		p := profiles[i]
+		cands := linkedin.CloseCandidates(p)
+
+		fmt.Fprintf(out, "\n[%d/%d] %s\n", n+1, len(pending), reviewLabel(p))
+		for j, u := range cands {
+			current := ""
+			if u == p.LinkedInURL {
+				current = "  (current)"
+			}
+			fmt.Fprintf(out, "  %d) %s%s\n", j+1, u, current)
+		}
+
+		for {
+			fmt.Fprintf(out, "choose 1-%d, s to skip, q to stop reviewing: ", len(cands))
+			if !sc.Scan() {
+				fmt.Fprintln(out)
+				return profiles
+			}
+			answer := strings.ToLower(strings.TrimSpace(sc.Text()))
+			if answer == "q" {
+				return profiles
+			}
+			if answer == "s" {
+				if p.ID != "" {
+					decisions.Set(p.ID, "")
+				}
+				break
+			}
+			if k, err := strconv.Atoi(answer); err == nil && k >= 1 && k <= len(cands) {
+				profiles[i] = linkedin.ChooseLinkedIn(p, cands[k-1])
+				if p.ID != "" {
+					decisions.Set(p.ID, cands[k-1])
+				}
+				break
+			}
+		}
+	}
+	return profiles
+}
+
+// reviewLabel describes p for a review prompt: its name, then its title,
+// company, and location where known.
+func reviewLabel(p scraper.Profile) string {
+	label := p.Name
+	if label == "" {
+		label = "(no name) " + p.ID
+	}
+	var about []string
+	for _, v := range []string{p.Title, p.Company, p.Location} {
+		if v != "" {
+			about = append(about, v)
+		}
+	}
+	if len(about) > 0 {
+		label += " - " + strings.Join(about, ", ")
+	}
+	return label
+}
+
+// isTerminal reports whether f is a terminal rather than a file, pipe, or
+// the null device, which cron jobs often get as their input.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
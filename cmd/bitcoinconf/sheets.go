@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/export"
+	"bitcoinconferencescraper/internal/scraper"
+	"bitcoinconferencescraper/internal/sheets"
+)
+
+// writeSheets writes profiles to the spreadsheet selected by the --sheets-*
+// flags, with the same columns as the CSV output, cut down to --fields.
+func (c *commonFlags) writeSheets(ctx context.Context, profiles []scraper.Profile) error {
+	if *c.sheetsCredentials == "" {
+		return fmt.Errorf("--sheets-id needs --sheets-credentials or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	creds, err := os.ReadFile(*c.sheetsCredentials)
+	if err != nil {
+		return err
+	}
+
+	// Not the run's shared client: its response cache would serve stale
+	// sheet contents.
+	httpClient := config.NewHTTPClient(time.Duration(*c.timeoutSec) * time.Second)
+	client, err := sheets.New(httpClient, *c.sheetsID, *c.sheetsTab, creds)
+	if err != nil {
+		return err
+	}
+
+	header := c.selected.PickCSV(export.CSVHeader)
+	rows := make([][]string, len(profiles))
+	for i, p := range profiles {
+		rows[i] = c.selected.PickCSV(export.CSVRecord(p))
+	}
+
+	if *c.sheetsAppend {
+		n, err := client.AppendNew(ctx, header, rows)
+		if err != nil {
+			return err
+		}
+		slog.Info("appended profiles to sheet", "new", n, "skipped", len(rows)-n, "sheet", *c.sheetsTab)
+		return nil
+	}
+
+	if err := client.Replace(ctx, header, rows); err != nil {
+		return err
+	}
+	slog.Info("wrote profiles to sheet", "profiles", len(rows), "sheet", *c.sheetsTab)
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package activehours restricts requests to a daily time-of-day window,
+// such as overnight in the event's time zone, so a long scrape stays off
+// the backend during its busy hours.
+package activehours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily span of wall-clock time in Loc, from Start up to End.
+// A Start after End spans midnight: 22:00-06:00 is open from 10pm until
+// 6am the next morning.
+type Window struct {
+	// Start and End are times of day, as offsets from midnight.
+	Start, End time.Duration
+	Loc        *time.Location
+}
+
+// Parse parses a window written as "HH:MM-HH:MM" in loc, or in local time
+// if loc is nil.
+func Parse(s string, loc *time.Location) (Window, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return Window{}, fmt.Errorf("active hours %q: want HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return Window{}, fmt.Errorf("active hours %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return Window{}, fmt.Errorf("active hours %q: %w", s, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("active hours %q: start and end are the same", s)
+	}
+	return Window{Start: start, End: end, Loc: loc}, nil
+}
+
+// parseClock parses a time of day such as "06:00" or "6:30".
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 06:00", strings.TrimSpace(s))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns w as Parse reads it.
+func (w Window) String() string {
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Until returns how long after t the window next opens, or 0 if it is
+// open at t.
+func (w Window) Until(t time.Time) time.Duration {
+	t = t.In(w.Loc)
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	open := now >= w.Start && now < w.End
+	if w.Start > w.End {
+		open = now >= w.Start || now < w.End
+	}
+	if open {
+		return 0
+	}
+
+	// Build the opening time from the wall clock, so days that are longer
+	// or shorter for daylight saving time still open at Start.
+	next := time.Date(t.Year(), t.Month(), t.Day(), int(w.Start.Hours()), int(w.Start.Minutes())%60, 0, 0, w.Loc)
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, int(w.Start.Hours()), int(w.Start.Minutes())%60, 0, 0, w.Loc)
+	}
+	return next.Sub(t)
+}
@@ -0,0 +1,73 @@
+// Package atomicfile replaces files in one step, so readers (and the next
+// run) see either the old complete file or the new complete file, never a
+// partially written one.
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// File is a temporary file in the target's directory that takes the
+// target's place on Commit.
+type File struct {
+	*os.File
+	path string
+	done bool
+}
+
+// Create starts writing a replacement for path. The temporary file gets
+// path's current permissions, or 0644 if path doesn't exist yet. Call
+// Commit once everything is written; Close without Commit discards the
+// temporary file and leaves path untouched.
+func Create(path string) (*File, error) {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &File{File: f, path: path}, nil
+}
+
+// Commit flushes the temporary file to disk and renames it over the
+// target path.
+func (f *File) Commit() error {
+	if f.done {
+		return errors.New("atomicfile: already closed")
+	}
+	f.done = true
+
+	if err := f.Sync(); err != nil {
+		f.File.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+// Close discards the temporary file unless Commit has already been called,
+// in which case it does nothing. It is safe to defer alongside Commit.
+func (f *File) Close() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
@@ -0,0 +1,52 @@
+// Package bodylimit caps how much of an HTTP response body is read, so a
+// broken or hostile server can't make the scraper buffer gigabytes.
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrTooLarge is returned (wrapped) by a body that exceeds its limit.
+var ErrTooLarge = errors.New("response too large")
+
+// Wrap returns body limited to max bytes: reading past that fails with an
+// error wrapping ErrTooLarge instead of returning more data. A max <= 0
+// returns body unchanged.
+func Wrap(body io.ReadCloser, max int64) io.ReadCloser {
+	if max <= 0 {
+		return body
+	}
+	return &reader{ReadCloser: body, max: max, remaining: max}
+}
+
+type reader struct {
+	io.ReadCloser
+	max       int64
+	remaining int64
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, r.tooLarge()
+	}
+	// Read one byte past the limit to tell a body of exactly max bytes
+	// from a longer one.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) > r.remaining {
+		n = int(r.remaining)
+		r.remaining = -1
+		return n, r.tooLarge()
+	}
+	r.remaining -= int64(n)
+	return n, err
+}
+
+func (r *reader) tooLarge() error {
+	return fmt.Errorf("%w: body exceeds %d bytes", ErrTooLarge, r.max)
+}
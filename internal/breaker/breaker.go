@@ -0,0 +1,182 @@
+// Package breaker implements a circuit breaker that stops sending requests
+// to a backend that keeps failing, so retries don't pile onto an overloaded
+// server.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker is open.
+var ErrOpen = errors.New("circuit breaker open")
+
+// State is the breaker's current mode.
+type State int
+
+const (
+	// Closed lets every request through and counts consecutive failures.
+	Closed State = iota
+	// Open fails requests fast until the cool-down has passed.
+	Open
+	// HalfOpen lets a single probe request through; its outcome decides
+	// whether the breaker closes or opens again.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// defaultMaxCooldown caps the cool-down when MaxCooldown is unset.
+const defaultMaxCooldown = 10 * time.Minute
+
+// Breaker opens after Threshold consecutive failures and stays open for
+// Cooldown, after which a single probe is let through. A successful probe
+// closes the breaker; a failed one opens it again with the cool-down
+// doubled, up to MaxCooldown. Any success while closed resets the failure
+// count.
+//
+// Configure the fields before first use; the breaker is then safe for
+// concurrent use. A Threshold <= 0 disables it.
+type Breaker struct {
+	Threshold   int
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	cooldown  time.Duration // current cool-down; grows while probes fail
+	openUntil time.Time
+	probing   bool
+}
+
+// Allow reports whether a request may be sent now. While the breaker is
+// open it returns an error wrapping ErrOpen and the time left until the
+// next probe. Once the cool-down has passed, the first caller becomes the
+// probe and gets HalfOpen; others keep failing fast until the probe's
+// outcome is recorded. Every allowed request must be followed by Record
+// or Release.
+func (b *Breaker) Allow() (State, time.Duration, error) {
+	if b == nil || b.Threshold <= 0 {
+		return Closed, 0, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if wait := time.Until(b.openUntil); wait > 0 {
+			return Open, wait, ErrOpen
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return HalfOpen, 0, nil
+	case HalfOpen:
+		if b.probing {
+			return HalfOpen, 0, ErrOpen
+		}
+		b.probing = true
+		return HalfOpen, 0, nil
+	}
+	return Closed, 0, nil
+}
+
+// Record reports the outcome of an allowed request and returns the state
+// before and after it, so callers can log transitions.
+func (b *Breaker) Record(failed bool) (from, to State) {
+	if b == nil || b.Threshold <= 0 {
+		return Closed, Closed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+	switch {
+	case !failed && b.state == HalfOpen:
+		b.state = Closed
+		b.failures = 0
+		b.cooldown = 0
+		b.probing = false
+	case !failed:
+		if b.state == Closed {
+			b.failures = 0
+		}
+	case b.state == HalfOpen:
+		b.cooldown = min(b.cooldown*2, b.maxCooldown())
+		b.open()
+	case b.state == Closed:
+		b.failures++
+		if b.failures >= b.Threshold {
+			b.cooldown = b.Cooldown
+			b.open()
+		}
+	}
+	return from, b.state
+}
+
+// Release ends an allowed request whose outcome says nothing about the
+// backend, such as one canceled by the caller. A probe slot is handed
+// back so the next caller can probe instead.
+func (b *Breaker) Release() {
+	if b == nil || b.Threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// CurrentCooldown returns how long the breaker stays open before the next
+// probe: Cooldown after the first trip, doubled for each failed probe.
+func (b *Breaker) CurrentCooldown() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cooldown
+}
+
+// ProbeIn returns how long until an open breaker lets its next probe
+// through, or 0 if requests are allowed now.
+func (b *Breaker) ProbeIn() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != Open {
+		return 0
+	}
+	return max(time.Until(b.openUntil), 0)
+}
+
+func (b *Breaker) open() {
+	if b.cooldown <= 0 {
+		b.cooldown = time.Second
+	}
+	b.state = Open
+	b.probing = false
+	b.openUntil = time.Now().Add(b.cooldown)
+}
+
+func (b *Breaker) maxCooldown() time.Duration {
+	if b.MaxCooldown > 0 {
+		return b.MaxCooldown
+	}
+	return defaultMaxCooldown
+}
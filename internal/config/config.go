@@ -1,23 +1,32 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Config is the scraper's configuration, read from BITCONF_* environment
+// variables. Credentials may also come from files; see SecretVars.
 type Config struct {
 	// APIBaseURL is the base URL of the backend API.
 	// For the Brella example, this would be:
 	//   https://api.brella.io
+	// It must be an absolute http or https URL; any trailing slash is
+	// removed.
 	APIBaseURL string
 
-	// EventID identifies the specific event whose attendees you are scraping.
-	// For example: AMS25.
-	EventID string
+	// EventIDs identifies the events whose attendees you are scraping, for
+	// example AMS25. BITCONF_EVENT_ID takes a comma-separated list to
+	// scrape several events into one output.
+	EventIDs []string
 
 	// AuthToken is an optional auth token or API key if required by the API.
 	AuthToken string
@@ -30,49 +39,239 @@ type Config struct {
 	ClientID    string
 	UID         string
 
+	// RefreshPath and RefreshToken configure automatic token refresh when
+	// Brella starts answering 401: RefreshToken is POSTed to RefreshPath
+	// (relative to APIBaseURL) and the returned tokens replace AccessToken,
+	// ClientID, and UID. Refresh is disabled if RefreshPath is empty.
+	RefreshPath  string
+	RefreshToken string
+
 	// SessionCookie is an optional _brella_session cookie value, if needed.
+	// BITCONF_SESSION_COOKIE may hold just the value or a whole Cookie
+	// header as copied from Proxyman ("Cookie: a=1; _brella_session=..."),
+	// from which the _brella_session value is picked out.
 	SessionCookie string
 
-	// BrellaMediaType is sent as x-brella-media-type; defaults to brella.latest
-	// if unset.
+	// Cookies is the full cookie string to send verbatim instead of just
+	// _brella_session. It is set from a whole Cookie header in
+	// BITCONF_SESSION_COOKIE when BITCONF_SEND_ALL_COOKIES is true.
+	Cookies string
+
+	// BrellaMediaType is sent as x-brella-media-type. Empty, from
+	// BITCONF_BRELLA_MEDIA_TYPE unset or set to auto, means it is to be
+	// detected with scraper.Client.DetectMediaType, falling back to
+	// scraper.DefaultBrellaMediaType.
 	BrellaMediaType string
 
+	// AcceptMediaType is the Accept header sent to Brella, which selects the
+	// API version. Empty (the default) leaves the client's built-in
+	// application/vnd.brella.v4+json.
+	AcceptMediaType string
+
+	// UserAgent, if set, is the User-Agent sent with Brella and search API
+	// requests, replacing the defaults (the scraper's own for Brella, a
+	// desktop browser's for DuckDuckGo).
+	UserAgent string
+
+	// ExtraHeaders are added to every Brella request, overriding built-in
+	// headers of the same name. BITCONF_EXTRA_HEADERS holds them as a JSON
+	// object or as "Name: value" lines, as copied from Proxyman.
+	ExtraHeaders map[string]string
+
+	// FieldMap maps profile fields to the Brella user attributes to read
+	// them from, for when Brella renames one. BITCONF_FIELD_MAP holds it as
+	// a JSON object or as "field: attribute" lines, such as
+	// "title: position".
+	FieldMap map[string]string
+
 	// RequestDelay is the pause between API requests, used to avoid
-	// hammering the Brella backend. Default is 1s.
+	// hammering the Brella backend. Default is 1s, or 0 when RateLimit is set.
 	RequestDelay time.Duration
 
+	// DelayJitter randomizes RequestDelay, SearchDelay, and the --verify-urls
+	// delay: each pause is drawn uniformly from delay*(1±DelayJitter), so
+	// 0.5 turns a 1s delay into anything between 500ms and 1.5s. It is a
+	// fraction between 0 and 1; default is 0 (fixed delays).
+	DelayJitter float64
+
+	// RateLimit caps the combined rate of all outbound requests (Brella and
+	// search, retries included) in requests per second. Zero means no
+	// global cap; only RequestDelay and SearchDelay apply.
+	RateLimit float64
+
+	// MaxRetries is how many times a failed Brella request (429, 5xx, or
+	// transient network error) is retried. Default is 3.
+	MaxRetries int
+
+	// RetryBudget caps the retries of all Brella requests in a run
+	// combined; once it is spent, failing requests are no longer retried.
+	// Zero, the default, means no cap beyond MaxRetries per request.
+	RetryBudget int
+
+	// RetryBaseDelay is the initial backoff between retries; it doubles on
+	// each attempt. Default is 500ms.
+	RetryBaseDelay time.Duration
+
+	// BreakerThreshold is how many consecutive 429, 5xx, or network
+	// failures open the Brella client's circuit breaker, after which
+	// requests fail fast for BreakerCooldown before a single probe is
+	// let through. Default is 5; 0 disables the breaker. BreakerCooldown
+	// defaults to 60s and doubles for each failed probe.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// SearchProvider selects the search backend for LinkedIn enrichment:
+	// SearchProviderGoogle or SearchProviderDuckDuckGo. If
+	// BITCONF_SEARCH_PROVIDER is unset, it is Google when both
+	// SearchAPIKey and SearchEngineID are set, and DuckDuckGo otherwise;
+	// SearchProviderAuto is then true.
+	SearchProvider     string
+	SearchProviderAuto bool
+
 	// SearchAPIKey and SearchEngineID are used for the web search API
 	// (for example, Google Custom Search) to look up public LinkedIn URLs.
-	// Both must be set for LinkedIn enrichment to run.
+	// With the Google provider, both must be set for LinkedIn enrichment
+	// to run.
 	SearchAPIKey   string
 	SearchEngineID string
 
-	// SearchDelay is the pause between search API requests.
+	// MaxResponseBytes caps the size of a Brella or search API response
+	// body; larger ones fail with a "response too large" error instead of
+	// being read into memory. Default is 10 MiB; 0 disables the limit.
+	MaxResponseBytes int64
+
+	// RequestTimeout bounds each Brella request attempt (list or detail).
+	// A timed-out attempt is retried. Zero leaves only the HTTP client's
+	// overall timeout in effect.
+	RequestTimeout time.Duration
+
+	// SearchRequestTimeout bounds each search API request, and replaces
+	// --timeout-sec as the search HTTP client's timeout, so it may be
+	// longer than the Brella requests are allowed. It is read from
+	// BITCONF_SEARCH_REQUEST_TIMEOUT_MS or, in whole seconds, from
+	// BITCONF_SEARCH_TIMEOUT_SEC. Zero leaves --timeout-sec in effect.
+	SearchRequestTimeout time.Duration
+
+	// SearchDelay is the pause between search API requests. Default is 1s,
+	// or 0 when RateLimit is set.
 	SearchDelay time.Duration
+
+	// SearchQueryTemplates replace the built-in LinkedIn search queries
+	// with text/template queries over the profile's fields, tried in
+	// order (see linkedin.ParseQueryTemplates). BITCONF_SEARCH_QUERIES
+	// holds one per line. Empty keeps the built-in queries.
+	SearchQueryTemplates []string
+
+	// SearchMaxResults is how many results of one LinkedIn query are
+	// looked through, paging past the first page while no confident match
+	// has been found. Each page is a separate request against the quota.
+	// Default is 10 (one Google page); Google serves at most 100. Only the
+	// Google provider pages.
+	SearchMaxResults int
 }
 
+// DefaultMaxResponseBytes is the MaxResponseBytes used when
+// BITCONF_MAX_RESPONSE_BYTES is unset.
+const DefaultMaxResponseBytes = 10 << 20
+
+// Search providers accepted in BITCONF_SEARCH_PROVIDER.
+const (
+	// SearchProviderGoogle uses the Google Custom Search JSON API and needs
+	// BITCONF_SEARCH_API_KEY and BITCONF_SEARCH_ENGINE_ID.
+	SearchProviderGoogle = "google"
+
+	// SearchProviderDuckDuckGo scrapes DuckDuckGo's HTML results and needs
+	// no key. Automated queries are against DuckDuckGo's terms of service;
+	// see linkedin.DuckDuckGoProvider.
+	SearchProviderDuckDuckGo = "duckduckgo"
+)
+
 // FromEnv loads configuration from environment variables.
 func FromEnv() (Config, error) {
-	baseURL := os.Getenv("BITCONF_API_BASE_URL")
-	if baseURL == "" {
-		return Config{}, errors.New("BITCONF_API_BASE_URL is not set")
+	return fromEnv(true)
+}
+
+// SearchFromEnv loads configuration like FromEnv but for runs that only
+// enrich existing profiles: BITCONF_API_BASE_URL and BITCONF_EVENT_ID may
+// be unset, though a base URL that is set must still be valid.
+func SearchFromEnv() (Config, error) {
+	return fromEnv(false)
+}
+
+func fromEnv(requireBrella bool) (Config, error) {
+	var baseURL string
+	if raw := os.Getenv("BITCONF_API_BASE_URL"); requireBrella || raw != "" {
+		var err error
+		baseURL, err = parseBaseURL(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("BITCONF_API_BASE_URL: %w", err)
+		}
 	}
 
-	eventID := os.Getenv("BITCONF_EVENT_ID")
-	if eventID == "" {
+	var eventIDs []string
+	for _, id := range strings.Split(os.Getenv("BITCONF_EVENT_ID"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			eventIDs = append(eventIDs, id)
+		}
+	}
+	if requireBrella && len(eventIDs) == 0 {
 		return Config{}, errors.New("BITCONF_EVENT_ID is not set")
 	}
 
-	authToken := os.Getenv("BITCONF_API_AUTH_TOKEN")
+	secrets := make(map[string]string, len(SecretVars))
+	for _, name := range SecretVars {
+		v, err := secretEnv(name)
+		if err != nil {
+			return Config{}, err
+		}
+		secrets[name] = v
+	}
+
+	authToken := secrets["BITCONF_API_AUTH_TOKEN"]
 
-	accessToken := os.Getenv("BITCONF_ACCESS_TOKEN")
+	accessToken := secrets["BITCONF_ACCESS_TOKEN"]
 	clientID := os.Getenv("BITCONF_CLIENT")
 	uid := os.Getenv("BITCONF_UID")
-	sessionCookie := os.Getenv("BITCONF_SESSION_COOKIE")
+	sendAllCookies, _ := strconv.ParseBool(os.Getenv("BITCONF_SEND_ALL_COOKIES"))
+	sessionCookie, cookies, err := parseSessionCookie(secrets["BITCONF_SESSION_COOKIE"], sendAllCookies)
+	if err != nil {
+		return Config{}, fmt.Errorf("BITCONF_SESSION_COOKIE: %w", err)
+	}
+	refreshPath := os.Getenv("BITCONF_REFRESH_PATH")
+	refreshToken := secrets["BITCONF_REFRESH_TOKEN"]
+	if refreshPath != "" && !strings.HasPrefix(refreshPath, "/") {
+		refreshPath = "/" + refreshPath
+	}
+
+	brellaMediaType := strings.TrimSpace(os.Getenv("BITCONF_BRELLA_MEDIA_TYPE"))
+	if brellaMediaType == "auto" {
+		brellaMediaType = ""
+	}
+
+	acceptMediaType := strings.TrimSpace(os.Getenv("BITCONF_ACCEPT_MEDIA_TYPE"))
+
+	userAgent := strings.TrimSpace(os.Getenv("BITCONF_USER_AGENT"))
+	extraHeaders, err := parseExtraHeaders(os.Getenv("BITCONF_EXTRA_HEADERS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("BITCONF_EXTRA_HEADERS: %w", err)
+	}
+	fieldMap, err := parseFieldMap(os.Getenv("BITCONF_FIELD_MAP"))
+	if err != nil {
+		return Config{}, fmt.Errorf("BITCONF_FIELD_MAP: %w", err)
+	}
 
-	brellaMediaType := os.Getenv("BITCONF_BRELLA_MEDIA_TYPE")
-	if brellaMediaType == "" {
-		brellaMediaType = "brella.latest"
+	var rateLimit float64
+	if v := os.Getenv("BITCONF_RATE_LIMIT_RPS"); v != "" {
+		if rps, err := strconv.ParseFloat(v, 64); err == nil && rps > 0 {
+			rateLimit = rps
+		}
+	}
+
+	// With a global rate limit in place, the fixed per-request delays are
+	// off unless explicitly configured.
+	defaultDelay := 1000 * time.Millisecond
+	if rateLimit > 0 {
+		defaultDelay = 0
 	}
 
 	var requestDelay time.Duration
@@ -82,12 +281,102 @@ func FromEnv() (Config, error) {
 		}
 	}
 	if requestDelay == 0 {
-		requestDelay = 1000 * time.Millisecond
+		requestDelay = defaultDelay
+	}
+
+	maxRetries := 3
+	if v := os.Getenv("BITCONF_MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxRetries = n
+		}
+	}
+
+	var retryBudget int
+	if v := os.Getenv("BITCONF_RETRY_BUDGET"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("BITCONF_RETRY_BUDGET: want a non-negative integer, got %q", v)
+		}
+		retryBudget = n
+	}
+
+	var delayJitter float64
+	if v := os.Getenv("BITCONF_DELAY_JITTER"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			return Config{}, fmt.Errorf("BITCONF_DELAY_JITTER: want a fraction between 0 and 1, got %q", v)
+		}
+		delayJitter = f
+	}
+
+	var retryBaseDelay time.Duration
+	if d := os.Getenv("BITCONF_RETRY_BASE_DELAY_MS"); d != "" {
+		if ms, err := strconv.Atoi(d); err == nil && ms >= 0 {
+			retryBaseDelay = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if retryBaseDelay == 0 {
+		retryBaseDelay = 500 * time.Millisecond
+	}
+
+	breakerThreshold := 5
+	if v := os.Getenv("BITCONF_BREAKER_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			breakerThreshold = n
+		}
+	}
+
+	var breakerCooldown time.Duration
+	if d := os.Getenv("BITCONF_BREAKER_COOLDOWN_MS"); d != "" {
+		if ms, err := strconv.Atoi(d); err == nil && ms >= 0 {
+			breakerCooldown = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if breakerCooldown == 0 {
+		breakerCooldown = 60 * time.Second
+	}
+
+	maxResponseBytes := int64(DefaultMaxResponseBytes)
+	if v := os.Getenv("BITCONF_MAX_RESPONSE_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			maxResponseBytes = n
+		}
+	}
+
+	var requestTimeout time.Duration
+	if d := os.Getenv("BITCONF_REQUEST_TIMEOUT_MS"); d != "" {
+		if ms, err := strconv.Atoi(d); err == nil && ms >= 0 {
+			requestTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	var searchRequestTimeout time.Duration
+	if d := os.Getenv("BITCONF_SEARCH_REQUEST_TIMEOUT_MS"); d != "" {
+		if ms, err := strconv.Atoi(d); err == nil && ms >= 0 {
+			searchRequestTimeout = time.Duration(ms) * time.Millisecond
+		}
+	} else if s := os.Getenv("BITCONF_SEARCH_TIMEOUT_SEC"); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil && sec >= 0 {
+			searchRequestTimeout = time.Duration(sec) * time.Second
+		}
 	}
 
-	searchAPIKey := os.Getenv("BITCONF_SEARCH_API_KEY")
+	searchAPIKey := secrets["BITCONF_SEARCH_API_KEY"]
 	searchEngineID := os.Getenv("BITCONF_SEARCH_ENGINE_ID")
 
+	searchProvider := strings.ToLower(strings.TrimSpace(os.Getenv("BITCONF_SEARCH_PROVIDER")))
+	searchProviderAuto := searchProvider == ""
+	switch searchProvider {
+	case "":
+		searchProvider = SearchProviderDuckDuckGo
+		if searchAPIKey != "" && searchEngineID != "" {
+			searchProvider = SearchProviderGoogle
+		}
+	case SearchProviderGoogle, SearchProviderDuckDuckGo:
+	default:
+		return Config{}, fmt.Errorf("BITCONF_SEARCH_PROVIDER: unknown provider %q (want %s or %s)", searchProvider, SearchProviderGoogle, SearchProviderDuckDuckGo)
+	}
+
 	var searchDelay time.Duration
 	if d := os.Getenv("BITCONF_SEARCH_DELAY_MS"); d != "" {
 		if ms, err := strconv.Atoi(d); err == nil && ms >= 0 {
@@ -95,29 +384,198 @@ func FromEnv() (Config, error) {
 		}
 	}
 	if searchDelay == 0 {
-		searchDelay = 1000 * time.Millisecond
+		searchDelay = defaultDelay
+	}
+
+	var searchQueryTemplates []string
+	for _, line := range strings.Split(os.Getenv("BITCONF_SEARCH_QUERIES"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			searchQueryTemplates = append(searchQueryTemplates, line)
+		}
+	}
+
+	searchMaxResults := 10
+	if v := os.Getenv("BITCONF_SEARCH_MAX_RESULTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			searchMaxResults = min(n, 100)
+		}
 	}
 
 	return Config{
-		APIBaseURL:      baseURL,
-		EventID:         eventID,
-		AuthToken:       authToken,
-		AccessToken:     accessToken,
-		ClientID:        clientID,
-		UID:             uid,
-		SessionCookie:   sessionCookie,
-		BrellaMediaType: brellaMediaType,
-		RequestDelay:    requestDelay,
-		SearchAPIKey:    searchAPIKey,
-		SearchEngineID:  searchEngineID,
-		SearchDelay:     searchDelay,
+		APIBaseURL:           baseURL,
+		EventIDs:             eventIDs,
+		AuthToken:            authToken,
+		AccessToken:          accessToken,
+		ClientID:             clientID,
+		UID:                  uid,
+		SessionCookie:        sessionCookie,
+		Cookies:              cookies,
+		RefreshPath:          refreshPath,
+		RefreshToken:         refreshToken,
+		BrellaMediaType:      brellaMediaType,
+		AcceptMediaType:      acceptMediaType,
+		UserAgent:            userAgent,
+		ExtraHeaders:         extraHeaders,
+		FieldMap:             fieldMap,
+		RequestDelay:         requestDelay,
+		DelayJitter:          delayJitter,
+		RateLimit:            rateLimit,
+		MaxRetries:           maxRetries,
+		RetryBudget:          retryBudget,
+		RetryBaseDelay:       retryBaseDelay,
+		BreakerThreshold:     breakerThreshold,
+		BreakerCooldown:      breakerCooldown,
+		MaxResponseBytes:     maxResponseBytes,
+		RequestTimeout:       requestTimeout,
+		SearchProvider:       searchProvider,
+		SearchProviderAuto:   searchProviderAuto,
+		SearchAPIKey:         searchAPIKey,
+		SearchEngineID:       searchEngineID,
+		SearchDelay:          searchDelay,
+		SearchRequestTimeout: searchRequestTimeout,
+		SearchQueryTemplates: searchQueryTemplates,
+		SearchMaxResults:     searchMaxResults,
 	}, nil
 }
 
+// HasBrellaAuth reports whether any Brella credential (bearer token,
+// access-token/client/uid headers, or session cookie) is configured. The
+// attendee endpoints reject unauthenticated requests.
+func (c Config) HasBrellaAuth() bool {
+	return c.AuthToken != "" || c.AccessToken != "" || c.ClientID != "" || c.UID != "" || c.SessionCookie != "" || c.Cookies != ""
+}
+
+// sessionCookieName is the cookie Brella keeps its session in.
+const sessionCookieName = "_brella_session"
+
+// parseSessionCookie interprets BITCONF_SESSION_COOKIE. A bare value is
+// returned as session. A cookie string (anything with a ";" or starting
+// with "_brella_session=", optionally prefixed with "Cookie:") yields the
+// _brella_session value as session, or, with sendAll, the whole string as
+// cookies. A cookie string without _brella_session is an error unless
+// sendAll is set.
+func parseSessionCookie(raw string, sendAll bool) (session, cookies string, err error) {
+	raw = strings.TrimSpace(raw)
+	if name, rest, ok := strings.Cut(raw, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "cookie") {
+		raw = strings.TrimSpace(rest)
+	}
+	if raw == "" {
+		return "", "", nil
+	}
+	if !strings.Contains(raw, ";") && !strings.HasPrefix(raw, sessionCookieName+"=") {
+		return raw, "", nil
+	}
+
+	if sendAll {
+		return "", raw, nil
+	}
+	for _, part := range strings.Split(raw, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == sessionCookieName && value != "" {
+			return value, "", nil
+		}
+	}
+	return "", "", fmt.Errorf("cookie string has no %s cookie; set BITCONF_SEND_ALL_COOKIES=true to send it as is", sessionCookieName)
+}
+
+// parseExtraHeaders interprets BITCONF_EXTRA_HEADERS: either a JSON object
+// of header names to values, or one "Name: value" header per line. Blank
+// lines are skipped.
+func parseExtraHeaders(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	headers := make(map[string]string)
+	if strings.HasPrefix(raw, "{") {
+		if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		for _, line := range strings.Split(raw, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("header line %q is not \"Name: value\"", line)
+			}
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	for name := range headers {
+		if name == "" || strings.ContainsAny(name, " \t:") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return headers, nil
+}
+
+// parseFieldMap interprets BITCONF_FIELD_MAP: either a JSON object of
+// profile fields to attribute keys, or one "field: attribute" pair per
+// line. Blank lines are skipped. Which fields can be mapped is the
+// scraper's to check.
+func parseFieldMap(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	fields := make(map[string]string)
+	if strings.HasPrefix(raw, "{") {
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return fields, nil
+	}
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		field, key, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("field map line %q is not \"field: attribute\"", line)
+		}
+		fields[strings.TrimSpace(field)] = strings.TrimSpace(key)
+	}
+	return fields, nil
+}
+
+// parseBaseURL checks that raw is an absolute http(s) URL and returns it
+// without a trailing slash.
+func parseBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("not set")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("URL %q must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL %q has no host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("URL %q must not include a query or fragment", raw)
+	}
+
+	return strings.TrimRight(raw, "/"), nil
+}
+
 // NewHTTPClient returns an HTTP client with reasonable defaults for scraping.
-func NewHTTPClient(timeout time.Duration) *http.Client {
+// Requests go through proxies in turn (see RotatingProxy), or through the
+// proxy from the environment if there are none.
+func NewHTTPClient(timeout time.Duration, proxies ...*url.URL) *http.Client {
 	transport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: RotatingProxy(proxies),
 		DialContext: (&net.Dialer{
 			Timeout:   10 * time.Second,
 			KeepAlive: 30 * time.Second,
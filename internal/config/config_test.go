@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestSearchProviderDefault(t *testing.T) {
+	tests := []struct {
+		provider, key, engineID string
+		want                    string
+		auto                    bool
+	}{
+		{"", "", "", SearchProviderDuckDuckGo, true},
+		{"", "key", "", SearchProviderDuckDuckGo, true},
+		{"", "key", "cx", SearchProviderGoogle, true},
+		{"google", "", "", SearchProviderGoogle, false},
+		{"DuckDuckGo", "key", "cx", SearchProviderDuckDuckGo, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("BITCONF_SEARCH_PROVIDER", tt.provider)
+		t.Setenv("BITCONF_SEARCH_API_KEY", tt.key)
+		t.Setenv("BITCONF_SEARCH_ENGINE_ID", tt.engineID)
+		cfg, err := SearchFromEnv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.SearchProvider != tt.want || cfg.SearchProviderAuto != tt.auto {
+			t.Errorf("provider %q, key %q, engine ID %q: got %s (auto %t), want %s (auto %t)",
+				tt.provider, tt.key, tt.engineID, cfg.SearchProvider, cfg.SearchProviderAuto, tt.want, tt.auto)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// EnvVar is one environment variable setting.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// harLog is the part of a HAR (HTTP Archive) file EnvFromHAR reads.
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				Cookies []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"cookies"`
+			} `json:"request"`
+			Response struct {
+				Status int `json:"status"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harAttendeesPath matches the path of a Brella attendee list or detail
+// request, capturing the event ID.
+var harAttendeesPath = regexp.MustCompile(`^(.*)/api/events/([^/]+)/attendees(?:/[^/]+)?/?$`)
+
+// harHeaderVars maps request headers to the variables they configure.
+var harHeaderVars = []struct{ header, name string }{
+	{"access-token", "BITCONF_ACCESS_TOKEN"},
+	{"client", "BITCONF_CLIENT"},
+	{"uid", "BITCONF_UID"},
+	{"x-brella-media-type", "BITCONF_BRELLA_MEDIA_TYPE"},
+	{"user-agent", "BITCONF_USER_AGENT"},
+}
+
+// EnvFromHAR reads a HAR file, as exported by Proxyman or a browser's
+// developer tools, and returns the variables that repeat its last
+// successful Brella attendees request: BITCONF_API_BASE_URL and
+// BITCONF_EVENT_ID from the URL, and the credentials and Brella headers
+// the request was sent with. If no attendees request succeeded, the last
+// one is used. Headers the request didn't have are left out.
+func EnvFromHAR(r io.Reader) ([]EnvVar, error) {
+	var har harLog
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("decoding HAR: %w", err)
+	}
+
+	pick := -1
+	for i, e := range har.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || !strings.EqualFold(e.Request.Method, "GET") || !harAttendeesPath.MatchString(u.Path) {
+			continue
+		}
+		if pick < 0 || e.Response.Status == 200 || har.Log.Entries[pick].Response.Status != 200 {
+			pick = i
+		}
+	}
+	if pick < 0 {
+		return nil, errors.New("HAR has no Brella attendees request (GET /api/events/{id}/attendees)")
+	}
+
+	req := har.Log.Entries[pick].Request
+	u, _ := url.Parse(req.URL)
+	m := harAttendeesPath.FindStringSubmatch(u.Path)
+	vars := []EnvVar{
+		{"BITCONF_API_BASE_URL", u.Scheme + "://" + u.Host + m[1]},
+		{"BITCONF_EVENT_ID", m[2]},
+	}
+
+	headers := make(map[string]string)
+	for _, h := range req.Headers {
+		// HTTP/2 captures list pseudo-headers such as ":authority".
+		if name := strings.ToLower(h.Name); !strings.HasPrefix(name, ":") {
+			headers[name] = strings.TrimSpace(h.Value)
+		}
+	}
+
+	if token, ok := strings.CutPrefix(headers["authorization"], "Bearer "); ok {
+		vars = append(vars, EnvVar{"BITCONF_API_AUTH_TOKEN", strings.TrimSpace(token)})
+	}
+	for _, hv := range harHeaderVars {
+		if v := headers[hv.header]; v != "" {
+			vars = append(vars, EnvVar{hv.name, v})
+		}
+	}
+	if accept := headers["accept"]; strings.HasPrefix(accept, "application/vnd.") {
+		vars = append(vars, EnvVar{"BITCONF_ACCEPT_MEDIA_TYPE", accept})
+	}
+
+	session := ""
+	if cookie := headers["cookie"]; strings.Contains(cookie, sessionCookieName+"=") {
+		session, _, _ = parseSessionCookie(cookie, false)
+	}
+	for _, c := range req.Cookies {
+		if session == "" && c.Name == sessionCookieName {
+			session = c.Value
+		}
+	}
+	if session != "" {
+		vars = append(vars, EnvVar{"BITCONF_SESSION_COOKIE", session})
+	}
+	return vars, nil
+}
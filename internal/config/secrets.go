@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SecretVars are the environment variables holding credentials. Each can
+// also be given as a file: when NAME is unset, the contents of the file
+// named by NAME_FILE are used (BITCONF_SESSION_COOKIE_FILE=/run/secrets/
+// cookie), so the value never appears in the environment. LoadSecretsFile
+// reads several of them from one file.
+var SecretVars = []string{
+	"BITCONF_API_AUTH_TOKEN",
+	"BITCONF_ACCESS_TOKEN",
+	"BITCONF_SESSION_COOKIE",
+	"BITCONF_REFRESH_TOKEN",
+	"BITCONF_SEARCH_API_KEY",
+}
+
+// LoadSecretsFile sets the SecretVars listed in the file at path that
+// aren't already set in the environment. The file holds one NAME=value
+// per line, like a .env file: blank lines and lines starting with # are
+// skipped, and a value may be wrapped in single or double quotes. Other
+// names are an error, so the file can't quietly change non-secret
+// settings.
+func LoadSecretsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: want NAME=value", path, n)
+		}
+		name = strings.TrimSpace(name)
+		if !slices.Contains(SecretVars, name) {
+			return fmt.Errorf("%s:%d: %s is not a secret setting (want one of %s)", path, n, name, strings.Join(SecretVars, ", "))
+		}
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		if err := os.Setenv(name, unquote(strings.TrimSpace(value))); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// unquote strips one pair of matching single or double quotes around v.
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// secretEnv returns the value of the secret environment variable name or,
+// if it is unset, the contents of the file named by name+"_FILE" with
+// surrounding whitespace trimmed.
+func secretEnv(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Secrets returns c's credential values that are set, for masking them in
+// log output.
+func (c Config) Secrets() []string {
+	var out []string
+	for _, v := range []string{c.AuthToken, c.AccessToken, c.SessionCookie, c.Cookies, c.RefreshToken, c.SearchAPIKey} {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Mask returns a stand-in for secret v that is safe to log: "***" followed
+// by its last four characters when it is long enough that they give
+// nothing away, or just "***".
+func Mask(v string) string {
+	if len(v) < 16 {
+		return "***"
+	}
+	return "***" + v[len(v)-4:]
+}
@@ -0,0 +1,89 @@
+// Package decompress decodes gzip- and deflate-encoded HTTP response
+// bodies. Go's transport only does this itself when it set Accept-Encoding
+// on its own, which a custom transport or an explicit header defeats, and
+// some CDNs compress responses whether or not they were asked to.
+package decompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AcceptEncoding is the Accept-Encoding header value Accept sets.
+const AcceptEncoding = "gzip, deflate"
+
+// Accept asks for a compressed response to req. Responses to it must go
+// through Body.
+func Accept(req *http.Request) {
+	req.Header.Set("Accept-Encoding", AcceptEncoding)
+}
+
+// Body replaces resp.Body with one that decodes it according to
+// resp's Content-Encoding, and drops the header and Content-Length, which
+// describe the encoded body. Bodies that aren't encoded, or that the
+// transport already decoded, are left alone. An encoding other than gzip,
+// deflate, or identity is an error.
+//
+// Callers capping the body size should wrap resp.Body after calling Body,
+// so the cap applies to the decoded data.
+func Body(resp *http.Response) error {
+	enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	var r io.Reader
+	switch enc {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("gzip response body: %w", err)
+		}
+		r = zr
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but some servers send a
+		// raw deflate stream; the zlib header tells them apart.
+		br := bufio.NewReader(resp.Body)
+		if head, err := br.Peek(2); err == nil && isZlibHeader(head) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return fmt.Errorf("deflate response body: %w", err)
+			}
+			r = zr
+		} else {
+			r = flate.NewReader(br)
+		}
+	default:
+		return fmt.Errorf("unsupported response Content-Encoding %q", enc)
+	}
+
+	resp.Body = &body{Reader: r, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// isZlibHeader reports whether b starts with a zlib header: deflate as
+// the compression method and a valid header checksum.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// body is a decoded response body. Closing it closes the raw body.
+type body struct {
+	io.Reader
+	raw io.ReadCloser
+}
+
+func (b *body) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return b.raw.Close()
+}
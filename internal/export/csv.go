@@ -0,0 +1,123 @@
+package export
+
+import (
+	"encoding/csv"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// CSVHeader lists the CSV columns in output order.
+var CSVHeader = []string{
+	"id",
+	"name",
+	"title",
+	"company",
+	"email",
+	"location",
+	"linkedin_url",
+	"linkedin_status",
+	"possible_linkedin_urls",
+	"twitter",
+	"website",
+	"time_zone",
+	"role",
+	"industry",
+	"company_size",
+	"registered_at",
+	"event_ids",
+	"countries",
+	"interests",
+	"sources",
+}
+
+// CSV writes profiles as CSV with a header row. Multiple possible LinkedIn
+// URLs and event IDs are each joined into a single space-separated cell;
+// countries and interests, which contain spaces, are joined with "; ".
+type CSV struct {
+	// Path is the output file, or Stdout.
+	Path string
+	// Fields selects the columns written; nil writes all of them.
+	Fields Fields
+}
+
+// Write implements Exporter.
+func (e CSV) Write(profiles []scraper.Profile) error {
+	f, err := create(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cols := e.Fields.csvColumns()
+	w := csv.NewWriter(f)
+	if err := w.Write(pick(CSVHeader, cols)); err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		if err := w.Write(pick(CSVRecord(p), cols)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// CSVRecord returns p's fields in CSVHeader order.
+func CSVRecord(p scraper.Profile) []string {
+	return []string{
+		p.ID,
+		p.Name,
+		p.Title,
+		p.Company,
+		p.Email,
+		p.Location,
+		p.LinkedInURL,
+		formatStatus(p.LinkedInStatus),
+		strings.Join(p.PossibleLinkedInURLs, " "),
+		p.Twitter,
+		p.Website,
+		p.TimeZone,
+		p.Role,
+		p.Industry,
+		p.CompanySize,
+		formatTime(p.RegisteredAt),
+		strings.Join(p.EventIDs, " "),
+		strings.Join(p.Countries, "; "),
+		strings.Join(p.Interests, "; "),
+		formatSources(p.Sources),
+	}
+}
+
+// formatSources formats Profile.Sources as "field=source" pairs joined
+// with "; ", sorted by field.
+func formatSources(sources map[string]string) string {
+	pairs := make([]string, 0, len(sources))
+	for _, field := range slices.Sorted(maps.Keys(sources)) {
+		pairs = append(pairs, field+"="+sources[field])
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// formatStatus formats an HTTP status, or "" for 0 (not checked).
+func formatStatus(status int) string {
+	if status == 0 {
+		return ""
+	}
+	return strconv.Itoa(status)
+}
+
+// formatTime formats t as RFC 3339, or "" for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
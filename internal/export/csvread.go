@@ -0,0 +1,294 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// csvAliases maps common contact-list headers, normalized as by
+// normalizeHeader, to the CSVHeader column they fill. Headers that are
+// already CSVHeader names need no alias.
+var csvAliases = map[string]string{
+	"full_name":         "name",
+	"fullname":          "name",
+	"job_title":         "title",
+	"position":          "title",
+	"role":              "title",
+	"organization":      "company",
+	"organisation":      "company",
+	"company_name":      "company",
+	"employer":          "company",
+	"employees":         "company_size",
+	"headcount":         "company_size",
+	"email_address":     "email",
+	"e_mail":            "email",
+	"city":              "location",
+	"linkedin":          "linkedin_url",
+	"linkedin_profile":  "linkedin_url",
+	"twitter_handle":    "twitter",
+	"x":                 "twitter",
+	"website_url":       "website",
+	"url":               "website",
+	"timezone":          "time_zone",
+	"first":             "first_name",
+	"given_name":        "first_name",
+	"last":              "last_name",
+	"surname":           "last_name",
+	"family_name":       "last_name",
+	"possible_linkedin": "possible_linkedin_urls",
+}
+
+// CSVColumns maps CSV input headers to the CSVHeader column each one
+// fills, overriding the built-in matching. Keys are normalized headers;
+// see ParseCSVColumns.
+type CSVColumns map[string]string
+
+// ParseCSVColumns parses a comma-separated list of header=field pairs,
+// such as "Full Name=name,Organisation=company". Headers are matched
+// case-insensitively; fields are CSVHeader names, or first_name and
+// last_name, which are joined into name when no name column is mapped.
+// A field of "-" ignores the header.
+func ParseCSVColumns(v string) (CSVColumns, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	cols := CSVColumns{}
+	for _, pair := range strings.Split(v, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		header, field, ok := strings.Cut(pair, "=")
+		header = normalizeHeader(header)
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || header == "" || field == "" {
+			return nil, fmt.Errorf("column mapping %q: want header=field", strings.TrimSpace(pair))
+		}
+		if field != "-" && !readableCSVField(field) {
+			return nil, fmt.Errorf("column mapping %q: unknown field %q (valid: %s, first_name, last_name)", strings.TrimSpace(pair), field, strings.Join(CSVHeader, ", "))
+		}
+		cols[header] = field
+	}
+	return cols, nil
+}
+
+// ReadCSV reads profiles from CSV with a header row, such as a contact
+// list exported from elsewhere or an earlier CSV output. Each header is
+// matched to a field by cols when it's listed there, else by CSVHeader
+// name or a common alias ("Full Name", "Organization", "Job Title", ...);
+// other columns are ignored. Values are parsed the way CSV writes them,
+// so CSV output reads back unchanged.
+//
+// A name column (or first and last name columns) is required. Rows without
+// an ID get one derived from their name, company, and email, which stays
+// the same across runs so --append and --no-match-cache still line up.
+// Blank rows are skipped.
+func ReadCSV(r io.Reader, cols CSVColumns) ([]scraper.Profile, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, len(header))
+	mapped := make(map[string]bool)
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff") // Excel's byte order mark
+		}
+		field := csvField(normalizeHeader(h), cols)
+		if field == "" || mapped[field] {
+			continue
+		}
+		fields[i] = field
+		mapped[field] = true
+	}
+	if !mapped["name"] && !mapped["first_name"] && !mapped["last_name"] {
+		return nil, fmt.Errorf("no name column in CSV header %q (map one with header=name)", header)
+	}
+
+	var profiles []scraper.Profile
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var p scraper.Profile
+		var first, last string
+		blank := true
+		for i, v := range record {
+			v = strings.TrimSpace(v)
+			if i >= len(fields) || fields[i] == "" || v == "" {
+				continue
+			}
+			blank = false
+			switch fields[i] {
+			case "first_name":
+				first = v
+			case "last_name":
+				last = v
+			default:
+				if err := setCSVField(&p, fields[i], v); err != nil {
+					return nil, fmt.Errorf("CSV line %d: %s: %w", line, header[i], err)
+				}
+			}
+		}
+		if blank {
+			continue
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSpace(first + " " + last)
+		}
+		if p.ID == "" {
+			p.ID = csvID(p)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// normalizeHeader lowercases h and turns runs of spaces, hyphens,
+// underscores, and dots into single underscores, so "E-mail Address"
+// becomes "e_mail_address".
+func normalizeHeader(h string) string {
+	words := strings.FieldsFunc(strings.ToLower(h), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.'
+	})
+	return strings.Join(words, "_")
+}
+
+// csvField returns the field the normalized header h fills, or "" if the
+// column is ignored.
+func csvField(h string, cols CSVColumns) string {
+	if field, ok := cols[h]; ok {
+		if field == "-" {
+			return ""
+		}
+		return field
+	}
+	if alias, ok := csvAliases[h]; ok {
+		return alias
+	}
+	if readableCSVField(h) {
+		return h
+	}
+	return ""
+}
+
+// readableCSVField reports whether ReadCSV can fill field.
+func readableCSVField(field string) bool {
+	return field == "first_name" || field == "last_name" || slices.Contains(CSVHeader, field)
+}
+
+// setCSVField sets the CSVHeader column field of p from v, parsed the way
+// CSVRecord formats it.
+func setCSVField(p *scraper.Profile, field, v string) error {
+	switch field {
+	case "id":
+		p.ID = v
+	case "name":
+		p.Name = v
+	case "title":
+		p.Title = v
+	case "company":
+		p.Company = v
+	case "email":
+		p.Email = v
+	case "location":
+		p.Location = v
+	case "linkedin_url":
+		p.LinkedInURL = v
+	case "linkedin_status":
+		status, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid status %q", v)
+		}
+		p.LinkedInStatus = status
+	case "possible_linkedin_urls":
+		p.PossibleLinkedInURLs = strings.Fields(v)
+	case "twitter":
+		p.Twitter = v
+	case "website":
+		p.Website = v
+	case "time_zone":
+		p.TimeZone = v
+	case "role":
+		p.Role = v
+	case "industry":
+		p.Industry = v
+	case "company_size":
+		p.CompanySize = v
+	case "registered_at":
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		p.RegisteredAt = t
+	case "event_ids":
+		p.EventIDs = strings.Fields(v)
+	case "countries":
+		p.Countries = splitList(v)
+	case "interests":
+		p.Interests = splitList(v)
+	case "sources":
+		sources, err := parseSources(v)
+		if err != nil {
+			return err
+		}
+		p.Sources = sources
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
+
+// splitList splits a "; "-joined cell, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseSources parses the formatSources form, "field=source" pairs joined
+// with "; ".
+func parseSources(v string) (map[string]string, error) {
+	sources := make(map[string]string)
+	for _, pair := range splitList(v) {
+		field, source, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.New("sources: want field=source pairs")
+		}
+		sources[strings.TrimSpace(field)] = strings.TrimSpace(source)
+	}
+	return sources, nil
+}
+
+// csvID derives an ID for a CSV row from its name, company, and email.
+func csvID(p scraper.Profile) string {
+	key := strings.ToLower(strings.Join([]string{p.Name, p.Company, p.Email}, "\x00"))
+	sum := sha256.Sum256([]byte(key))
+	return "csv-" + hex.EncodeToString(sum[:8])
+}
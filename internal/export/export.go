@@ -0,0 +1,110 @@
+// Package export writes profiles in the CLI's output formats. Each format
+// is an Exporter, so callers can swap in their own sink without touching
+// the code that collects the profiles.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// Exporter writes a complete set of profiles to its destination, replacing
+// whatever an earlier Write put there.
+type Exporter interface {
+	Write(profiles []scraper.Profile) error
+}
+
+// Stdout is the path that writes to standard output.
+const Stdout = "-"
+
+// New returns the Exporter for format ("json", "csv", "ndjson", or
+// "vcard") that writes the selected fields to path.
+func New(format, path string, fields Fields) (Exporter, error) {
+	switch format {
+	case "json":
+		return JSON{Path: path, Fields: fields}, nil
+	case "csv":
+		return CSV{Path: path, Fields: fields}, nil
+	case "ndjson":
+		return NDJSON{Path: path, Fields: fields}, nil
+	case "vcard":
+		return VCard{Path: path, Fields: fields}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want json, csv, ndjson, or vcard)", format)
+	}
+}
+
+// CheckFormat reports whether New supports format.
+func CheckFormat(format string) error {
+	_, err := New(format, "", nil)
+	return err
+}
+
+// JSON writes profiles as one indented JSON array.
+type JSON struct {
+	// Path is the output file, or Stdout.
+	Path string
+	// Fields selects the fields written; nil writes all of them.
+	Fields Fields
+	// Events, if not nil, makes the output a Wrapper object describing
+	// the events next to the profiles instead of a bare array.
+	Events []scraper.Event
+}
+
+// Wrapper is the JSON output with event metadata.
+type Wrapper struct {
+	Events   []scraper.Event `json:"events"`
+	Profiles any             `json:"profiles"`
+}
+
+// Write implements Exporter.
+func (e JSON) Write(profiles []scraper.Profile) error {
+	f, err := create(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	out := e.Fields.profilesJSON(profiles)
+	if e.Events != nil {
+		out = Wrapper{Events: e.Events, Profiles: out}
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// outputFile is an output being written. Commit publishes it; Close
+// without Commit discards it.
+type outputFile interface {
+	io.Writer
+	Commit() error
+	Close() error
+}
+
+// create creates the output at path. It is written to a temporary file
+// that Commit renames over path, so a failed or interrupted write leaves
+// any existing file intact. For Stdout, writes go straight to standard
+// output.
+func create(path string) (outputFile, error) {
+	if path == Stdout {
+		return stdoutFile{os.Stdout}, nil
+	}
+	return atomicfile.Create(path)
+}
+
+// stdoutFile is standard output as an outputFile; Commit and Close do
+// nothing.
+type stdoutFile struct{ io.Writer }
+
+func (stdoutFile) Commit() error { return nil }
+func (stdoutFile) Close() error  { return nil }
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// ProfileFields lists the serialized names of scraper.Profile's fields in
+// struct order, read from their json tags, so new fields become
+// selectable without changes here.
+var ProfileFields = jsonFieldNames(reflect.TypeFor[scraper.Profile]())
+
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Fields is a set of profile fields to write, by their ProfileFields
+// names. A nil set selects every field.
+type Fields map[string]bool
+
+// ParseFields parses a comma-separated list of field names. Unknown names
+// are an error listing the valid ones; an empty list selects every field.
+func ParseFields(v string) (Fields, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+
+	valid := make(map[string]bool, len(ProfileFields))
+	for _, name := range ProfileFields {
+		valid[name] = true
+	}
+
+	set := Fields{}
+	var unknown []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "":
+		case valid[name]:
+			set[name] = true
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown field(s) %s (valid: %s)", strings.Join(unknown, ", "), strings.Join(ProfileFields, ", "))
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no fields selected (valid: %s)", strings.Join(ProfileFields, ", "))
+	}
+	return set, nil
+}
+
+// profileJSON returns the value to encode for p: p itself when every field
+// is selected, or a wrapper that only serializes the selected fields.
+func (s Fields) profileJSON(p scraper.Profile) any {
+	if s == nil {
+		return p
+	}
+	return selectedProfile{p: p, fields: s}
+}
+
+// profilesJSON is profileJSON for a whole slice.
+func (s Fields) profilesJSON(profiles []scraper.Profile) any {
+	if s == nil {
+		return profiles
+	}
+	out := make([]any, len(profiles))
+	for i, p := range profiles {
+		out[i] = s.profileJSON(p)
+	}
+	return out
+}
+
+// csvColumns returns the indexes of the CSVHeader columns that are
+// selected, or nil when every column is.
+func (s Fields) csvColumns() []int {
+	if s == nil {
+		return nil
+	}
+	cols := []int{}
+	for i, name := range CSVHeader {
+		if s[name] {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// PickCSV returns the selected columns of record, a row in CSVHeader
+// order, for writers outside this package. With s nil it returns record.
+func (s Fields) PickCSV(record []string) []string {
+	return pick(record, s.csvColumns())
+}
+
+// pick returns the entries of record at cols, or record itself if cols is
+// nil.
+func pick(record []string, cols []int) []string {
+	if cols == nil {
+		return record
+	}
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = record[c]
+	}
+	return out
+}
+
+// selectedProfile serializes only the selected fields of a profile, in
+// struct order and with Profile's own omitempty rules.
+type selectedProfile struct {
+	p      scraper.Profile
+	fields Fields
+}
+
+func (sp selectedProfile) MarshalJSON() ([]byte, error) {
+	full, err := json.Marshal(sp.p)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(full, &values); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, name := range ProfileFields {
+		v, ok := values[name]
+		if !ok || !sp.fields[name] {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
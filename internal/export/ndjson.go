@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// NDJSON writes profiles as newline-delimited JSON, one profile object per
+// line.
+type NDJSON struct {
+	// Path is the output file, or Stdout.
+	Path string
+	// Fields selects the fields written; nil writes all of them.
+	Fields Fields
+}
+
+// Write implements Exporter.
+func (e NDJSON) Write(profiles []scraper.Profile) error {
+	f, err := create(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := NewNDJSONWriter(f, e.Fields)
+	for _, p := range profiles {
+		if err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return f.Commit()
+}
+
+// NDJSONWriter writes profiles one line at a time. Over a file from
+// CreateStream, output written so far survives if the process dies
+// mid-run.
+type NDJSONWriter struct {
+	enc    *json.Encoder
+	fields Fields
+}
+
+// NewNDJSONWriter returns an NDJSONWriter writing the selected fields to w.
+func NewNDJSONWriter(w io.Writer, fields Fields) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w), fields: fields}
+}
+
+// Write encodes p as a single line. Each line goes straight to the
+// underlying writer rather than through a buffer.
+func (w *NDJSONWriter) Write(p scraper.Profile) error {
+	return w.enc.Encode(w.fields.profileJSON(p))
+}
+
+// CreateStream opens path for writing in place, so whatever has been
+// written survives the process dying mid-run, unlike the atomic writes of
+// the Exporters. Standard output is returned for Stdout, with a Close that
+// leaves it open.
+func CreateStream(path string) (io.WriteCloser, error) {
+	if path == Stdout {
+		return stdoutFile{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+// AppendStream is CreateStream but adds to the file at path, creating it if
+// needed, instead of truncating it.
+func AppendStream(path string) (io.WriteCloser, error) {
+	if path == Stdout {
+		return stdoutFile{os.Stdout}, nil
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+}
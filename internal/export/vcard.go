@@ -0,0 +1,213 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// VCard writes profiles as vCard 3.0 (RFC 2426) contacts for importing
+// into contacts apps and CRMs: FN and N from the name, ORG, TITLE, EMAIL,
+// a URL each for the LinkedIn profile and website, the Twitter account as
+// X-SOCIALPROFILE, interests as CATEGORIES, and the remaining details in
+// NOTE. The profile ID is the UID, so re-importing updates contacts rather
+// than duplicating them.
+type VCard struct {
+	// Path is the output file, or Stdout; with Split, the directory the
+	// files are written to.
+	Path string
+	// Fields selects the fields written; nil writes all of them. A
+	// contact always gets an FN, falling back to the company or ID when
+	// the name isn't selected.
+	Fields Fields
+	// Split writes each profile to a file of its own in Path, named after
+	// its ID, instead of all of them to one file. Files of profiles not
+	// written this time are left in place.
+	Split bool
+}
+
+// Write implements Exporter.
+func (e VCard) Write(profiles []scraper.Profile) error {
+	if e.Split {
+		return e.writeDir(profiles)
+	}
+
+	f, err := create(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, p := range profiles {
+		if _, err := f.Write(e.card(p)); err != nil {
+			return err
+		}
+	}
+	return f.Commit()
+}
+
+// writeDir is Write for Split.
+func (e VCard) writeDir(profiles []scraper.Profile) error {
+	if e.Path == Stdout {
+		return fmt.Errorf("one vCard file per profile needs a directory, not stdout")
+	}
+	if err := os.MkdirAll(e.Path, 0o755); err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		if err := writeFile(filepath.Join(e.Path, vcardFileName(p.ID)), e.card(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes data to path via atomicfile.
+func writeFile(path string, data []byte) error {
+	f, err := atomicfile.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// vcardFileName returns the file name for the contact with id, with
+// characters that aren't safe in file names replaced.
+func vcardFileName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, id)
+	if strings.Trim(name, ".") == "" {
+		name = "profile"
+	}
+	return name + ".vcf"
+}
+
+// card returns the vCard of p.
+func (e VCard) card(p scraper.Profile) []byte {
+	has := func(field string) bool { return e.Fields == nil || e.Fields[field] }
+
+	var b strings.Builder
+	line := func(prop, value string) {
+		if value != "" {
+			b.WriteString(foldVCardLine(prop + ":" + value))
+		}
+	}
+
+	b.WriteString("BEGIN:VCARD\r\nVERSION:3.0\r\n")
+	if has("id") {
+		line("UID", vcardEscape(p.ID))
+	}
+
+	name := ""
+	if has("name") {
+		name = p.Name
+	}
+	fn := name
+	for _, v := range []string{p.Company, p.ID} {
+		if fn == "" {
+			fn = v
+		}
+	}
+	line("FN", vcardEscape(fn))
+	// N is required; given names go first and the family name last.
+	family, given := "", name
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		given, family = name[:i], name[i+1:]
+	}
+	b.WriteString(foldVCardLine("N:" + vcardEscape(family) + ";" + vcardEscape(given) + ";;;"))
+
+	if has("company") {
+		line("ORG", vcardEscape(p.Company))
+	}
+	if has("title") {
+		line("TITLE", vcardEscape(p.Title))
+	}
+	if has("email") {
+		line("EMAIL;TYPE=INTERNET", vcardEscape(p.Email))
+	}
+	if has("linkedin_url") {
+		line("URL", vcardEscape(p.LinkedInURL))
+	}
+	if has("website") {
+		line("URL", vcardEscape(p.Website))
+	}
+	if has("twitter") {
+		line("X-SOCIALPROFILE;TYPE=twitter", vcardEscape(p.Twitter))
+	}
+	if has("interests") && len(p.Interests) > 0 {
+		cats := make([]string, len(p.Interests))
+		for i, v := range p.Interests {
+			cats[i] = vcardEscape(v)
+		}
+		line("CATEGORIES", strings.Join(cats, ","))
+	}
+	line("NOTE", vcardEscape(e.note(p, has)))
+	b.WriteString("END:VCARD\r\n")
+	return []byte(b.String())
+}
+
+// note returns the NOTE of p: the selected details that have no vCard
+// property of their own, one per line.
+func (e VCard) note(p scraper.Profile, has func(string) bool) string {
+	var lines []string
+	add := func(field, label, value string) {
+		if has(field) && value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+	add("role", "Role", p.Role)
+	add("location", "Location", p.Location)
+	add("countries", "Countries", strings.Join(p.Countries, "; "))
+	add("time_zone", "Time zone", p.TimeZone)
+	add("industry", "Industry", p.Industry)
+	add("company_size", "Company size", p.CompanySize)
+	add("possible_linkedin_urls", "Possible LinkedIn URLs", strings.Join(p.PossibleLinkedInURLs, " "))
+	add("event_ids", "Events", strings.Join(p.EventIDs, " "))
+	add("registered_at", "Registered", formatTime(p.RegisteredAt))
+	return strings.Join(lines, "\n")
+}
+
+// vcardEscape escapes a text value: backslashes, commas, and semicolons
+// are backslash-escaped and line breaks become \n.
+func vcardEscape(s string) string {
+	return vcardEscaper.Replace(s)
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// foldVCardLine returns content line l terminated by CRLF, folded into
+// lines of at most 75 octets, each continuation starting with a space.
+// Lines are only broken between characters.
+func foldVCardLine(l string) string {
+	const maxLine = 75
+	var b strings.Builder
+	limit := maxLine
+	for len(l) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(l[cut]) {
+			cut--
+		}
+		b.WriteString(l[:cut])
+		b.WriteString("\r\n ")
+		l = l[cut:]
+		// The leading space counts toward the limit.
+		limit = maxLine - 1
+	}
+	b.WriteString(l)
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,47 @@
+// Package fold reduces names to unaccented lowercase words, so spellings of
+// the same name with and without accents compare equal.
+package fold
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Words folds s to unaccented lowercase and splits it into words on
+// anything that isn't a letter or digit. Accents are removed by
+// decomposing s (NFD) and dropping the combining marks, so precomposed
+// and decomposed spellings fold alike. Apostrophes join rather than
+// split, so "O'Brien" is one word, and "José García" and "jose garcia"
+// both give [jose garcia].
+func Words(s string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == '\'' || r == '’':
+			continue
+		case foldRunes[r] != "":
+			b.WriteString(foldRunes[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Letters without an ASCII folding (for example CJK) are kept
+			// as is; they just won't match an ASCII slug.
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+// foldRunes maps lowercase Latin letters that don't decompose into a base
+// letter and marks to their ASCII spelling.
+var foldRunes = map[rune]string{
+	'đ': "d", 'ð': "d",
+	'ı': "i",
+	'ł': "l",
+	'ø': "o",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th",
+}
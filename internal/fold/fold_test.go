@@ -0,0 +1,49 @@
+package fold
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"José García", []string{"jose", "garcia"}},
+		{"jose garcia", []string{"jose", "garcia"}},
+		{"Garci\u0301a", []string{"garcia"}},
+		{"Jose\u0301 Garci\u0301a", []string{"jose", "garcia"}},
+		{"Zoë Ångström", []string{"zoe", "angstrom"}},
+		{"Zoe\u0308 A\u030angstro\u0308m", []string{"zoe", "angstrom"}},
+		{"Łukasz Żółć", []string{"lukasz", "zolc"}},
+		{"Søren Ørsted", []string{"soren", "orsted"}},
+		{"Straße", []string{"strasse"}},
+		{"Đorđe Čavić", []string{"dorde", "cavic"}},
+		{"Ștefan Țurcan", []string{"stefan", "turcan"}},
+		{"Nguyễn Thị Minh", []string{"nguyen", "thi", "minh"}},
+		{"O'Brien", []string{"obrien"}},
+		{"O’Brien-García", []string{"obrien", "garcia"}},
+		{"Dr. Ada  Lovelace, PhD", []string{"dr", "ada", "lovelace", "phd"}},
+		{"田中 太郎", []string{"田中", "太郎"}},
+		{"Agent 007", []string{"agent", "007"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWordsComposedAndDecomposedMatch(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"García", "Garci\u0301a"},
+		{"Müller", "Mu\u0308ller"},
+		{"Ñúñez", "N\u0303u\u0301n\u0303ez"},
+	} {
+		if a, b := Words(pair[0]), Words(pair[1]); !slices.Equal(a, b) {
+			t.Errorf("Words(%q) = %q but Words(%q) = %q", pair[0], a, pair[1], b)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package httpcache provides an http.RoundTripper that keeps successful GET
+// responses on disk so repeated runs don't refetch them.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Transport caches 200 responses to GET requests in Dir, keyed by the full
+// request URL. Other methods, other statuses and transport errors pass
+// through untouched. Request headers are not part of the key, so the cache
+// should only be shared between runs using the same credentials.
+type Transport struct {
+	// Dir is the directory cached responses are stored in. It is created
+	// on first write.
+	Dir string
+
+	// TTL is how long a cached response is served before it is fetched
+	// again. If TTL <= 0, cached responses never expire.
+	TTL time.Duration
+
+	// MaxBodyBytes, if > 0, is the largest response body that is cached.
+	// Larger responses are passed through uncached, having buffered no
+	// more than MaxBodyBytes of them.
+	MaxBodyBytes int64
+
+	// Next performs requests that miss the cache. Defaults to
+	// http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next().RoundTrip(req)
+	}
+
+	path := t.path(req)
+	if resp, ok := t.load(path, req); ok {
+		return resp, nil
+	}
+
+	resp, err := t.next().RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	if t.MaxBodyBytes > 0 {
+		head, err := io.ReadAll(io.LimitReader(resp.Body, t.MaxBodyBytes+1))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if int64(len(head)) > t.MaxBodyBytes {
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+			return resp, nil
+		}
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(head))
+	}
+
+	// DumpResponse reads the body and replaces it with an in-memory copy,
+	// so resp can still be returned to the caller afterwards.
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	// A failed cache write only costs a refetch next time.
+	_ = t.store(path, dump)
+	return resp, nil
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next == nil {
+		return http.DefaultTransport
+	}
+	return t.Next
+}
+
+// path returns the cache file for req.
+func (t *Transport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:]))
+}
+
+// load returns the cached response at path if it exists and is still fresh.
+func (t *Transport) load(path string, req *http.Request) (*http.Response, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if t.TTL > 0 && time.Since(info.ModTime()) > t.TTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+// store writes dump to path via a temporary file so a concurrent reader
+// never sees a partial response.
+func (t *Transport) store(path string, dump []byte) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(t.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(dump); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,244 @@
+// Package httplog provides an http.RoundTripper that logs every request
+// and response, for debugging what was actually sent and received.
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/decompress"
+)
+
+// DefaultMaxBody is how much of each body is logged when
+// Transport.MaxBody is 0.
+const DefaultMaxBody = 2048
+
+// sensitiveHeaders are masked with config.Mask wherever they appear.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"access-token":        true,
+	"client":              true,
+	"uid":                 true,
+	"x-api-key":           true,
+	"x-goog-api-key":      true,
+}
+
+// sensitiveParams are query parameters masked in logged URLs, such as the
+// search API key.
+var sensitiveParams = map[string]bool{
+	"key":          true,
+	"api_key":      true,
+	"apikey":       true,
+	"access_token": true,
+	"token":        true,
+}
+
+// sensitiveFields are JSON object keys whose string values are masked in
+// logged bodies, such as the tokens a refresh request sends and gets back.
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"access-token":  true,
+	"refresh_token": true,
+	"refresh-token": true,
+	"token":         true,
+	"client":        true,
+	"uid":           true,
+	"password":      true,
+	"secret":        true,
+}
+
+// jsonStringField matches a JSON string member, capturing its key and
+// value. It also finds members in a body cut off at MaxBody.
+var jsonStringField = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)"((?:[^"\\]|\\.)*)"`)
+
+// Transport logs each request's method, URL, and headers, and its
+// response's status, duration, and headers, with the start of both bodies,
+// as one debug-level record per round trip. Credentials in headers, query
+// parameters, and JSON body fields are masked. A compressed response body
+// is shown decoded; the response itself is passed on as received.
+type Transport struct {
+	// Logger receives the records. Defaults to slog.Default().
+	Logger *slog.Logger
+
+	// MaxBody is how many bytes of each body are logged; longer bodies
+	// are cut off and marked as such. 0 means DefaultMaxBody.
+	MaxBody int
+
+	// Next performs the requests. Defaults to http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := t.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !logger.Enabled(req.Context(), slog.LevelDebug) {
+		return t.next().RoundTrip(req)
+	}
+
+	attrs := []any{
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		headerGroup("request_headers", req.Header),
+	}
+	if body := t.requestBody(req); body != "" {
+		attrs = append(attrs, "request_body", body)
+	}
+
+	start := time.Now()
+	resp, err := t.next().RoundTrip(req)
+	attrs = append(attrs, "duration", time.Since(start))
+	if err != nil {
+		logger.DebugContext(req.Context(), "http request failed", append(attrs, "err", err)...)
+		return resp, err
+	}
+
+	attrs = append(attrs, "status", resp.StatusCode, headerGroup("response_headers", resp.Header))
+	body, err := t.responseBody(resp)
+	if err != nil {
+		logger.DebugContext(req.Context(), "reading http response failed", append(attrs, "err", err)...)
+		return nil, err
+	}
+	attrs = append(attrs, "response_body", body)
+	logger.DebugContext(req.Context(), "http request", attrs...)
+	return resp, nil
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next == nil {
+		return http.DefaultTransport
+	}
+	return t.Next
+}
+
+func (t *Transport) maxBody() int {
+	if t.MaxBody <= 0 {
+		return DefaultMaxBody
+	}
+	return t.MaxBody
+}
+
+// requestBody returns the start of req's body for the log, read from a
+// copy so the request is sent as is. Bodies that can't be copied are left
+// out.
+func (t *Transport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	head, _ := io.ReadAll(io.LimitReader(rc, int64(t.maxBody())+1))
+	return t.preview(head)
+}
+
+// responseBody reads the start of resp's body for the log and puts it
+// back in front of the rest.
+func (t *Transport) responseBody(resp *http.Response) (string, error) {
+	head, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBody())+1))
+	if err != nil {
+		resp.Body.Close()
+		return "", err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	// Decode a copy of what was read; a cut-off stream still decodes up
+	// to where it was cut.
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		decoded := &http.Response{
+			Header: http.Header{"Content-Encoding": {enc}},
+			Body:   io.NopCloser(bytes.NewReader(head)),
+		}
+		if err := decompress.Body(decoded); err != nil {
+			return "(" + err.Error() + ")", nil
+		}
+		head, _ = io.ReadAll(io.LimitReader(decoded.Body, int64(t.maxBody())+1))
+	}
+	return t.preview(head), nil
+}
+
+// preview returns head as text cut off at MaxBody bytes, or a note that
+// it isn't text.
+func (t *Transport) preview(head []byte) string {
+	cut := len(head) > t.maxBody()
+	if cut {
+		head = head[:t.maxBody()]
+		// Don't leave half a character at the end.
+		for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.Valid(head); i++ {
+			head = head[:len(head)-1]
+		}
+	}
+	if !utf8.Valid(head) {
+		return "(binary body)"
+	}
+	s := redactBody(string(head))
+	if cut {
+		s += "...(truncated)"
+	}
+	return s
+}
+
+// redactBody returns body with the string values of sensitiveFields
+// masked.
+func redactBody(body string) string {
+	return jsonStringField.ReplaceAllStringFunc(body, func(m string) string {
+		sub := jsonStringField.FindStringSubmatch(m)
+		if !sensitiveFields[strings.ToLower(sub[1])] {
+			return m
+		}
+		return `"` + sub[1] + `"` + sub[2] + `"` + config.Mask(sub[3]) + `"`
+	})
+}
+
+// redactURL returns u as a string with sensitive query parameters
+// masked.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		name, value, _ := strings.Cut(param, "=")
+		if n, err := url.QueryUnescape(name); err == nil && sensitiveParams[strings.ToLower(n)] {
+			params[i] = name + "=" + config.Mask(value)
+		}
+	}
+	c := *u
+	c.RawQuery = strings.Join(params, "&")
+	return c.String()
+}
+
+// headerGroup returns h as a group attribute with lowercase header names
+// and sensitive values masked.
+func headerGroup(key string, h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		v := strings.Join(h[name], ", ")
+		name = strings.ToLower(name)
+		if sensitiveHeaders[name] {
+			v = config.Mask(v)
+		}
+		attrs = append(attrs, slog.String(name, v))
+	}
+	return slog.Group(key, attrs...)
+}
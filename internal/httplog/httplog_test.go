@@ -0,0 +1,67 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"refresh_token":"r-secret-token-1234"}`, `{"refresh_token":"***1234"}`},
+		{`{"access-token": "abc", "client": "xyz", "uid": "ada@example.com", "name": "Ada"}`,
+			`{"access-token": "***", "client": "***", "uid": "***", "name": "Ada"}`},
+		{`{"data":{"Access_Token":"with \"quotes\" inside-0123456789"}}`, `{"data":{"Access_Token":"***6789"}}`},
+		{`{"tags":["uid","client"],"title":"token"}`, `{"tags":["uid","client"],"title":"token"}`},
+		{`{"access_token":"cut off mid`, `{"access_token":"cut off mid`},
+		{`plain text uid: 12`, `plain text uid: 12`},
+	}
+	for _, tt := range tests {
+		if got := redactBody(tt.in); got != tt.want {
+			t.Errorf("redactBody(%s)\n got %s\nwant %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransportMasksRefreshExchange(t *testing.T) {
+	const (
+		refreshToken = "refresh-0123456789abcdef"
+		accessToken  = "access-fedcba9876543210"
+		clientID     = "client-5555666677778888"
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token":"`+accessToken+`","client":"`+clientID+`","uid":"ada@example.com"}`)
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	client := &http.Client{Transport: &Transport{
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}}
+	resp, err := client.Post(srv.URL+"/auth/refresh", "application/json", strings.NewReader(`{"refresh_token":"`+refreshToken+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), accessToken) {
+		t.Errorf("response body %s was changed", body)
+	}
+	for _, secret := range []string{refreshToken, accessToken, clientID, "ada@example.com"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("log contains %q:\n%s", secret, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), "***cdef") || !strings.Contains(logs.String(), "***3210") {
+		t.Errorf("log doesn't show the masked tokens:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,84 @@
+// Package jitter spaces requests apart by a randomized interval, so a run's
+// request timing doesn't tick like a metronome.
+package jitter
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Pacer lets callers through one at a time, each at least an interval
+// after the previous one. Every interval is drawn uniformly from
+// [d*(1-fraction), d*(1+fraction)], so with fraction 0 it is exactly d and
+// with 0.5 a 1s delay varies between 500ms and 1.5s. The first Wait
+// returns immediately.
+//
+// A Pacer is safe for concurrent use; callers share the pacing, so running
+// more of them doesn't raise the overall rate.
+type Pacer struct {
+	d        time.Duration
+	fraction float64
+
+	mu   sync.Mutex
+	rng  *rand.Rand
+	next time.Time // earliest time the next caller may go
+}
+
+// New returns a Pacer for delay d and jitter fraction, which is clamped to
+// [0, 1]. A non-positive d never waits.
+func New(d time.Duration, fraction float64) *Pacer {
+	return NewSeeded(d, fraction, rand.Uint64())
+}
+
+// NewSeeded is like New but draws intervals from a generator seeded with
+// seed, so the same seed always yields the same sequence of delays.
+func NewSeeded(d time.Duration, fraction float64, seed uint64) *Pacer {
+	return &Pacer{
+		d:        d,
+		fraction: min(max(fraction, 0), 1),
+		rng:      rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Wait blocks until the caller's turn or until ctx is done, returning
+// ctx's error in that case.
+func (p *Pacer) Wait(ctx context.Context) error {
+	if p.d <= 0 {
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval())
+	p.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// interval draws the gap before the next caller. p.mu must be held.
+func (p *Pacer) interval() time.Duration {
+	if p.fraction == 0 {
+		return p.d
+	}
+	spread := float64(p.d) * p.fraction
+	return time.Duration(float64(p.d) - spread + 2*spread*p.rng.Float64())
+}
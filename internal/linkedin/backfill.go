@@ -0,0 +1,138 @@
+package linkedin
+
+import (
+	"maps"
+	"regexp"
+	"strings"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+var (
+	// linkedInTitleSuffix is the site name ending a LinkedIn result title.
+	linkedInTitleSuffix = regexp.MustCompile(`(?i)\s*[|\-–—]\s*LinkedIn\s*$`)
+	// titleSeparator splits "Name - Headline - Company" titles.
+	titleSeparator = regexp.MustCompile(`\s+[-–—|]\s+`)
+	// snippetExperience finds the current employer LinkedIn lists in
+	// result descriptions ("Experience: Acme Corp · Education: ...").
+	snippetExperience = regexp.MustCompile(`(?i)\bExperience:\s*([^·|]+)`)
+)
+
+// backfill fills p's blank Company and Title from the search result of its
+// LinkedInURL, when the name leading that result's title is p's, and
+// records scraper.SourceLinkedIn for each field it fills in p.Sources. It
+// returns the names of the fields filled. Fields Brella already gave are
+// never touched.
+func (m *Matcher) backfill(p scraper.Profile, results []Result) (scraper.Profile, []string) {
+	if p.LinkedInURL == "" || (p.Company != "" && p.Title != "") {
+		return p, nil
+	}
+
+	var match *Result
+	for i := range results {
+		if results[i].URL == p.LinkedInURL {
+			match = &results[i]
+			break
+		}
+	}
+	if match == nil {
+		return p, nil
+	}
+
+	name, headline, company := parseLinkedInResult(*match)
+	if !sameName(p.Name, name) {
+		m.Logger.Debug("result title doesn't name the person; not backfilling", "name", p.Name, "id", p.ID, "title", match.Title)
+		return p, nil
+	}
+
+	var filled []string
+	fill := func(field string, dst *string, v string) {
+		if *dst != "" || v == "" {
+			return
+		}
+		*dst = v
+		if p.Sources == nil {
+			p.Sources = make(map[string]string)
+		} else {
+			// Don't write through to the caller's map.
+			p.Sources = maps.Clone(p.Sources)
+		}
+		p.Sources[field] = scraper.SourceLinkedIn
+		filled = append(filled, field)
+	}
+	fill("company", &p.Company, company)
+	fill("title", &p.Title, headline)
+
+	if len(filled) > 0 {
+		m.Logger.Info("filled fields from linkedin", "name", p.Name, "id", p.ID, "fields", filled, "company", p.Company, "title", p.Title)
+	}
+	return p, filled
+}
+
+// parseLinkedInResult reads the name, headline, and current company from a
+// LinkedIn profile search result. Titles look like "Name - Headline -
+// Company | LinkedIn" or "Name - Company | LinkedIn"; the "Experience:"
+// entry of the snippet, when present, names the company more reliably.
+// Parts search engines cut short ("Acme Corpo...") are not used.
+func parseLinkedInResult(r Result) (name, headline, company string) {
+	title := linkedInTitleSuffix.ReplaceAllString(strings.TrimSpace(r.Title), "")
+	parts := titleSeparator.Split(title, -1)
+	truncated := false
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if isTruncated(parts[i]) {
+			parts, truncated = parts[:i], true
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "", "", ""
+	}
+	name = parts[0]
+
+	switch {
+	case len(parts) >= 3:
+		headline = strings.Join(parts[1:len(parts)-1], " - ")
+		company = parts[len(parts)-1]
+	case len(parts) == 2 && truncated:
+		// The company, if any, was cut off.
+		headline = parts[1]
+	case len(parts) == 2:
+		if role, at, ok := strings.Cut(parts[1], " at "); ok {
+			headline, company = strings.TrimSpace(role), strings.TrimSpace(at)
+		} else {
+			company = parts[1]
+		}
+	}
+
+	if m := snippetExperience.FindStringSubmatch(r.Snippet); m != nil {
+		if v := strings.TrimSpace(m[1]); v != "" && !isTruncated(v) {
+			company = strings.TrimRight(v, ".")
+		}
+	}
+	return name, headline, company
+}
+
+// isTruncated reports whether s was cut short with an ellipsis.
+func isTruncated(s string) bool {
+	return strings.HasSuffix(s, "...") || strings.HasSuffix(s, "…")
+}
+
+// sameName reports whether every part of want (as nameTokens splits it)
+// appears in got, so "Jane Doe" matches "Jane A. Doe, CFA".
+func sameName(want, got string) bool {
+	parts := nameTokens(want)
+	if len(parts) == 0 {
+		return false
+	}
+	have := make(map[string]bool)
+	for _, t := range nameTokens(got) {
+		have[t] = true
+	}
+	for _, p := range parts {
+		if !have[p] {
+			return false
+		}
+	}
+	return true
+}
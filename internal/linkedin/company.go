@@ -0,0 +1,83 @@
+package linkedin
+
+import (
+	"regexp"
+	"strings"
+
+	"bitcoinconferencescraper/internal/fold"
+)
+
+// legalSuffixes are company-form words dropped from the end of a company
+// name for search, compared folded (see fold.Words) with dots removed, so
+// "GmbH", "INC." and "S.à r.l." all match.
+var legalSuffixes = map[string]bool{
+	"ab":           true,
+	"ag":           true,
+	"bv":           true,
+	"co":           true,
+	"corp":         true,
+	"corporation":  true,
+	"gmbh":         true,
+	"inc":          true,
+	"incorporated": true,
+	"kk":           true,
+	"limited":      true,
+	"llc":          true,
+	"llp":          true,
+	"lp":           true,
+	"ltd":          true,
+	"nv":           true,
+	"oy":           true,
+	"plc":          true,
+	"pte":          true,
+	"pty":          true,
+	"sa":           true,
+	"sarl":         true,
+	"sas":          true,
+	"spa":          true,
+	"srl":          true,
+}
+
+// parenthetical matches an aside such as "(formerly Foo)" in a company name.
+var parenthetical = regexp.MustCompile(`\([^()]*\)`)
+
+// normalizeCompany returns the form of a Brella company name used in search
+// queries. Legal-form suffixes ("Inc.", ", LLC", "Pty Ltd", "GmbH"), in
+// any case and with or without accents, are dropped from the end, along
+// with an "&" they leave behind ("Smith & Co"), as are parenthetical
+// asides, double quotes, and punctuation around words, and runs of space
+// are collapsed. Punctuation inside words is kept, so "Bitcoin.com" and
+// "AT&T" survive. The result is lowercased, so spellings that differ only
+// in case, such as "ACME, Inc" and "Acme Inc.", give the same query. The
+// first word is never dropped, even if it looks like a suffix.
+//
+//	"ACME, Inc"              -> "acme"
+//	"Acme Incorporated"      -> "acme"
+//	"Foo Holdings Pty. Ltd." -> "foo holdings"
+func normalizeCompany(company string) string {
+	var words []string
+	for _, w := range strings.Fields(strings.ReplaceAll(parenthetical.ReplaceAllString(company, " "), `"`, " ")) {
+		if w = strings.Trim(w, ",;:.()[]!?*|/"); w != "" {
+			words = append(words, w)
+		}
+	}
+
+	n := len(words)
+	for n > 1 {
+		switch {
+		case isLegalSuffix(words[n-1]) || words[n-1] == "&":
+			n--
+		case n > 2 && isLegalSuffix(words[n-2]+words[n-1]):
+			// A suffix written as two words, such as "S.à r.l.".
+			n -= 2
+		default:
+			return strings.ToLower(strings.Join(words[:n], " "))
+		}
+	}
+	return strings.ToLower(strings.Join(words[:n], " "))
+}
+
+// isLegalSuffix reports whether word is one of legalSuffixes.
+func isLegalSuffix(word string) bool {
+	return legalSuffixes[strings.Join(fold.Words(word), "")]
+}
@@ -0,0 +1,47 @@
+package linkedin
+
+import "testing"
+
+func TestNormalizeCompany(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// Legal-form suffixes, in any case and punctuation, with the
+		// result lowercased.
+		{"Acme Inc.", "acme"},
+		{"ACME, Inc", "acme"},
+		{"Acme Incorporated", "acme"},
+		{"acme inc", "acme"},
+		{"Acme INC.", "acme"},
+		{"Foo Holdings Pty. Ltd.", "foo holdings"},
+		{"Blockstream Corp", "blockstream"},
+		{"Swan Bitcoin, LLC", "swan bitcoin"},
+		{"Bitcoin Suisse AG", "bitcoin suisse"},
+		{"Trezor GmbH", "trezor"},
+		{"Trezor GMBH", "trezor"},
+		{"Neutrino Oy", "neutrino"},
+		{"Ledger S.A.S.", "ledger"},
+		{"Société Générale S.A.", "société générale"},
+		{"Luxor S.à r.l.", "luxor"},
+		{"Luxor S.à.r.l.", "luxor"},
+		{"Smith & Co", "smith"},
+		{"Smith & Co.", "smith"},
+		// Asides, quotes, and stray punctuation.
+		{`"River" Financial (formerly Foo)`, "river financial"},
+		{"  Acme   Labs  ", "acme labs"},
+		{"Acme | Labs!", "acme labs"},
+		// Punctuation inside words, and a first word that looks like a
+		// suffix, survive.
+		{"Bitcoin.com", "bitcoin.com"},
+		{"AT&T Inc.", "at&t"},
+		{"Inc.", "inc"},
+		{"Co", "co"},
+		{"Ledger", "ledger"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCompany(tt.in); got != tt.want {
+			t.Errorf("normalizeCompany(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,193 +2,718 @@ package linkedin
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"maps"
 	"net/http"
-	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"golang.org/x/time/rate"
+
+	"bitcoinconferencescraper/internal/bodylimit"
 	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/decompress"
+	"bitcoinconferencescraper/internal/jitter"
+	"bitcoinconferencescraper/internal/metrics"
 	"bitcoinconferencescraper/internal/scraper"
+	"bitcoinconferencescraper/internal/throttle"
 )
 
-// Matcher uses a web search API (for example, Google Custom Search)
-// to find public LinkedIn profile URLs for attendees.
+// ErrSearchQuotaExceeded is returned (wrapped) when the search API refuses
+// a query because its quota is used up, such as a Google 403
+// dailyLimitExceeded or a 429. Further searches will fail the same way
+// until the quota resets, so enrichment stops even with ContinueOnError.
+var ErrSearchQuotaExceeded = errors.New("search API quota exceeded")
+
+// ErrNoResultsStreak is returned (wrapped) when StopAfterNoResults
+// searches in a row found nothing, which more likely means the search API
+// is quietly returning empty pages than that none of those people are on
+// LinkedIn. Like ErrSearchQuotaExceeded, it stops enrichment even with
+// ContinueOnError.
+var ErrNoResultsStreak = errors.New("too many searches in a row without results")
+
+// Matcher uses a web search provider (Google Custom Search by default) to
+// find public LinkedIn profile URLs for attendees, and with EnrichTwitter
+// their Twitter/X accounts.
 //
 // You must configure a compliant search API and respect its terms
 // of service and rate limits.
 type Matcher struct {
 	httpClient *http.Client
 
-	searchAPIKey   string
-	searchEngineID string
-	searchDelay    time.Duration
-	enabled        bool
+	provider      SearchProvider
+	searchDelay   *jitter.Pacer
+	delayJitter   float64
+	searchTimeout time.Duration
+	userAgent     string
+	maxBody       int64
+	maxResults    int
+	enabled       bool
+
+	// Limiter, if set, is waited on before every search API request. Pass
+	// the same limiter as the scraper's Client to cap the combined rate.
+	Limiter *rate.Limiter
+
+	// Concurrency is the number of profiles searched at once. The search
+	// delay and Limiter are shared by all workers, so raising it doesn't
+	// raise the overall request rate beyond them. Values <= 1 search one
+	// profile at a time.
+	Concurrency int
+
+	// VerifyNames checks personal profile candidates against the person's
+	// name: the candidate whose URL slug best fits the name becomes the
+	// primary LinkedInURL, and candidates whose slug shares no part of the
+	// name are never made primary (they stay in PossibleLinkedInURLs).
+	VerifyNames bool
+
+	// ContinueOnError makes EnrichProfiles log a failed search and move on
+	// to the next profile instead of stopping. Failed profiles are not
+	// marked as searched, so a rerun retries them. Running out of search
+	// quota still stops the run.
+	ContinueOnError bool
+
+	// StopAfterNoResults, if > 0, stops EnrichProfiles with an error
+	// wrapping ErrNoResultsStreak once that many searches in a row came
+	// back empty. The profiles of that streak are left unsearched, so a
+	// rerun searches them again once the search API is fixed.
+	StopAfterNoResults int
+
+	// SearchCandidates makes EnrichProfiles search profiles that have
+	// PossibleLinkedInURLs but no LinkedInURL, such as ones imported from
+	// a file without their search state. They are skipped by default,
+	// since their earlier search already found what it could and another
+	// costs search quota.
+	SearchCandidates bool
+
+	// IncludeCompanyPages keeps linkedin.com company pages (/company/,
+	// /showcase/, /school/) among a profile's search candidates, after its
+	// personal ones. By default only personal profiles, /in/ and the
+	// legacy /pub/, are kept; other linkedin.com results are dropped.
+	IncludeCompanyPages bool
+
+	// QueryTemplates, if set, replace the built-in LinkedIn queries: each
+	// is rendered for the profile (see ParseQueryTemplates) and they are
+	// tried in order until one finds linkedin.com results. A template that
+	// renders empty, or the same as an earlier one, is skipped, so optional
+	// parts can be wrapped in {{if .Company}}...{{end}}. Their variants in
+	// EnrichmentStats are "template-1", "template-2", and so on.
+	QueryTemplates []*template.Template
+
+	// Backfill fills a matched profile's blank Company and Title from the
+	// title and snippet of its LinkedIn search result, when the result
+	// names the person, and marks them with scraper.SourceLinkedIn in
+	// Sources. Values from Brella are never replaced.
+	Backfill bool
+
+	// Throttle paces searches from the rate-limit headers on the search
+	// API's responses, pausing until the quota resets once it is used up.
+	// NewMatcher sets one; nil disables it.
+	Throttle *throttle.Throttle
+
+	// VerifyDelay is the least time between the requests VerifyURLs makes
+	// to LinkedIn.
+	VerifyDelay time.Duration
+
+	// NoMatchCache, if set, makes EnrichProfiles skip profiles whose
+	// LinkedIn search found nothing within the cache's TTL, and records
+	// each new search outcome in it. It is saved when EnrichProfiles
+	// returns. Twitter enrichment doesn't use it.
+	NoMatchCache *NoMatchCache
+
+	// Metrics, if set, counts search requests by provider and status.
+	Metrics metrics.Recorder
+
+	// Logger receives match results and query details. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
 }
 
 // NewMatcher constructs a new Matcher instance using the provided HTTP client
-// and configuration. If the search API key or engine ID are missing, the
-// matcher is disabled and EnrichProfiles will be a no-op.
+// and configuration. cfg.SearchProvider selects the backend: "duckduckgo"
+// for DuckDuckGoProvider, otherwise GoogleProvider. With Google, if the
+// search API key or engine ID are missing, the matcher is disabled and
+// EnrichProfiles will be a no-op. cfg.UserAgent, if set, replaces the
+// provider's User-Agent on every search request, and cfg.MaxResponseBytes
+// caps the size of result pages. cfg.SearchMaxResults sets how many results
+// a LinkedIn query may page through with a PagingProvider.
 func NewMatcher(httpClient *http.Client, cfg config.Config) *Matcher {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
 
-	enabled := cfg.SearchAPIKey != "" && cfg.SearchEngineID != ""
+	var provider SearchProvider
+	var enabled bool
+	switch cfg.SearchProvider {
+	case config.SearchProviderDuckDuckGo:
+		provider = DuckDuckGoProvider{}
+		enabled = true
+	default:
+		provider = GoogleProvider{APIKey: cfg.SearchAPIKey, EngineID: cfg.SearchEngineID}
+		enabled = cfg.SearchAPIKey != "" && cfg.SearchEngineID != ""
+	}
 
 	return &Matcher{
-		httpClient:     httpClient,
-		searchAPIKey:   cfg.SearchAPIKey,
-		searchEngineID: cfg.SearchEngineID,
-		searchDelay:    cfg.SearchDelay,
-		enabled:        enabled,
+		httpClient:    httpClient,
+		provider:      provider,
+		searchDelay:   jitter.New(cfg.SearchDelay, cfg.DelayJitter),
+		delayJitter:   cfg.DelayJitter,
+		searchTimeout: cfg.SearchRequestTimeout,
+		userAgent:     cfg.UserAgent,
+		maxBody:       cfg.MaxResponseBytes,
+		maxResults:    cfg.SearchMaxResults,
+		enabled:       enabled,
+		Throttle:      &throttle.Throttle{},
+		Logger:        slog.Default(),
+	}
+}
+
+// Query variants tried by findLinkedInCandidates, in order, when no
+// QueryTemplates are set. They are the keys of
+// EnrichmentStats.MatchesByVariant, along with the template variants.
+const (
+	VariantNameCompany  = "name+company"
+	VariantName         = "name"
+	VariantNameUnquoted = "name-unquoted"
+)
+
+// EnrichmentStats tallies what EnrichProfiles did with each profile.
+type EnrichmentStats struct {
+	// AlreadyLinked counts profiles that had a LinkedInURL on input.
+	AlreadyLinked int
+	// PreviouslySearched counts profiles skipped because an earlier run
+	// already searched for them.
+	PreviouslySearched int
+	// NoName counts profiles skipped because they have no name to search.
+	NoName int
+	// RecentNoMatch counts profiles skipped because NoMatchCache says a
+	// recent search found nothing for them.
+	RecentNoMatch int
+	// HasCandidates counts profiles skipped because they have possible
+	// LinkedIn URLs though no LinkedInURL, without SearchCandidates.
+	HasCandidates int
+
+	// Matched counts profiles that got a personal /in/ URL from search.
+	Matched int
+	// CandidatesOnly counts profiles for which search found no /in/
+	// profile, only legacy /pub/ ones or, with IncludeCompanyPages,
+	// company pages.
+	CandidatesOnly int
+	// NoResults counts profiles for which search found nothing.
+	NoResults int
+	// Failed counts profiles whose search failed with ContinueOnError set.
+	Failed int
+	// Backfilled counts matched profiles that got a Company or Title from
+	// their LinkedIn result with Backfill set.
+	Backfilled int
+
+	// MatchesByVariant counts, per query variant, the searched profiles
+	// whose results (a match or candidates) came from that variant.
+	MatchesByVariant map[string]int
+}
+
+// String formats s as a one-line summary.
+func (s EnrichmentStats) String() string {
+	// Built-in variants in the order they are tried, then any others
+	// (query templates) by name.
+	order := []string{VariantNameCompany, VariantName, VariantNameUnquoted}
+	for _, v := range slices.Sorted(maps.Keys(s.MatchesByVariant)) {
+		if !slices.Contains(order, v) {
+			order = append(order, v)
+		}
+	}
+	var variants []string
+	for _, v := range order {
+		if n := s.MatchesByVariant[v]; n > 0 {
+			variants = append(variants, fmt.Sprintf("%s %d", v, n))
+		}
+	}
+	by := ""
+	if len(variants) > 0 {
+		by = " (" + strings.Join(variants, ", ") + ")"
+	}
+	failed := ""
+	if s.Failed > 0 {
+		failed = fmt.Sprintf(", %d failed", s.Failed)
 	}
+	recent := ""
+	if s.RecentNoMatch > 0 {
+		recent = fmt.Sprintf(", %d recently without results", s.RecentNoMatch)
+	}
+	candidates := ""
+	if s.HasCandidates > 0 {
+		candidates = fmt.Sprintf(", %d skipped with candidates", s.HasCandidates)
+	}
+	backfilled := ""
+	if s.Backfilled > 0 {
+		backfilled = fmt.Sprintf(" (%d backfilled)", s.Backfilled)
+	}
+	return fmt.Sprintf("%d already linked, %d previously searched%s%s, %d without a name, %d matched%s, %d candidates only%s, %d no results%s",
+		s.AlreadyLinked, s.PreviouslySearched, recent, candidates, s.NoName, s.Matched, backfilled, s.CandidatesOnly, by, s.NoResults, failed)
+}
+
+// Enabled reports whether a search API is configured.
+func (m *Matcher) Enabled() bool {
+	return m.enabled
 }
 
 // EnrichProfiles attaches LinkedIn URLs to profiles where possible.
 //
-// For each profile with an empty LinkedInURL, it issues a search query
-// like: `"Name" "Company" site:linkedin.com/in` and picks the first
-// linkedin.com/in/... result, if any.
-func (m *Matcher) EnrichProfiles(ctx context.Context, profiles []scraper.Profile) ([]scraper.Profile, error) {
+// For each profile with an empty LinkedInURL that hasn't been searched
+// before (LinkedInSearched is false) and, unless SearchCandidates is set,
+// has no PossibleLinkedInURLs either, it issues a search query
+// like: `"Name" "Company" site:linkedin.com/in`, with the company
+// lowercased and its legal suffix and stray punctuation removed ("ACME,
+// Inc." is searched as "acme"), and picks the first linkedin.com/in/...
+// result, if any. Profiles keep their original Company. Result URLs are
+// normalized first, and only personal /in/ profiles are eligible for the
+// primary LinkedInURL; other personal ones (legacy /pub/ profiles) and,
+// with IncludeCompanyPages, company pages are kept in PossibleLinkedInURLs.
+// Other linkedin.com results, such as posts or job ads, are dropped. Every
+// returned profile's PossibleLinkedInURLs are normalized and deduplicated,
+// and never repeat its LinkedInURL. With Backfill, a match's blank
+// Company and Title may be filled from its result.
+//
+// Up to Concurrency profiles are searched at once; results are written back
+// in input order. The first search error stops the run unless
+// ContinueOnError is set; an error wrapping ErrSearchQuotaExceeded or
+// ErrNoResultsStreak always stops it. The returned stats cover the profiles
+// processed before any error.
+func (m *Matcher) EnrichProfiles(ctx context.Context, profiles []scraper.Profile) ([]scraper.Profile, EnrichmentStats, error) {
+	stats := EnrichmentStats{MatchesByVariant: make(map[string]int)}
+
 	if !m.enabled {
-		log.Printf("linkedin: search API not configured; skipping LinkedIn enrichment")
-		return profiles, nil
+		m.Logger.Info("search API not configured; skipping LinkedIn enrichment")
+		return profiles, stats, nil
 	}
 
 	out := make([]scraper.Profile, len(profiles))
 	copy(out, profiles)
 
+	var pending []int
 	for i, p := range out {
-		if p.LinkedInURL != "" || strings.TrimSpace(p.Name) == "" {
-			continue
+		switch {
+		case p.LinkedInURL != "":
+			stats.AlreadyLinked++
+		case p.LinkedInSearched:
+			stats.PreviouslySearched++
+		case len(p.PossibleLinkedInURLs) > 0 && !m.SearchCandidates:
+			stats.HasCandidates++
+		case strings.TrimSpace(p.Name) == "":
+			stats.NoName++
+		case m.NoMatchCache != nil && m.NoMatchCache.Recent(p.ID):
+			stats.RecentNoMatch++
+		default:
+			pending = append(pending, i)
 		}
+	}
 
-		urls, err := m.findLinkedInCandidates(ctx, p)
+	var (
+		mu     sync.Mutex // guards stats and streak
+		streak []int      // indexes of the latest searches in a row without results
+	)
+	failed, err := m.eachPending(ctx, out, pending, func(ctx context.Context, i int) error {
+		p := out[i]
+		results, variant, err := m.findLinkedInCandidates(ctx, p)
 		if err != nil {
-			// Stop on first search error so the caller can
-			// persist partial results and optionally resume later.
-			return out, fmt.Errorf("search error for %q (%s): %w", p.Name, p.ID, err)
+			return err
 		}
+		urls := make([]string, len(results))
+		for j, r := range results {
+			urls[j] = r.URL
+		}
+
+		out[i] = m.applyCandidates(p, urls)
+		var filled []string
+		if m.Backfill {
+			out[i], filled = m.backfill(out[i], results)
+		}
+		if m.NoMatchCache != nil {
+			m.NoMatchCache.Record(p.ID, len(urls) > 0)
+		}
+
+		mu.Lock()
+		m.record(&stats, out[i], variant, len(urls))
+		if len(filled) > 0 {
+			stats.Backfilled++
+		}
+		defer mu.Unlock()
+
 		if len(urls) > 0 {
-			// First candidate is used as the primary URL.
-			out[i].LinkedInURL = urls[0]
-			// Any additional candidates go into PossibleLinkedInURLs.
-			if len(urls) > 1 {
-				out[i].PossibleLinkedInURLs = urls[1:]
+			streak = streak[:0]
+			return nil
+		}
+		streak = append(streak, i)
+		if m.StopAfterNoResults <= 0 || len(streak) < m.StopAfterNoResults {
+			return nil
+		}
+
+		// Searches finished by other workers don't touch these indexes
+		// again, so they can be put back.
+		for _, j := range streak {
+			out[j] = profiles[j]
+			if m.NoMatchCache != nil {
+				m.NoMatchCache.Forget(out[j].ID)
+			}
+		}
+		stats.NoResults -= len(streak)
+		m.Logger.Warn("searches in a row found nothing; stopping enrichment, the search API may be returning empty results", "searches", len(streak), "last", p.Name)
+		return fmt.Errorf("%w (%d)", ErrNoResultsStreak, len(streak))
+	})
+	stats.Failed = failed
+
+	// Older runs and other tools may have left repeats behind.
+	for i := range out {
+		out[i].PossibleLinkedInURLs = dedupeCandidates(out[i].LinkedInURL, out[i].PossibleLinkedInURLs)
+	}
+
+	if m.NoMatchCache != nil {
+		if saveErr := m.NoMatchCache.Save(); saveErr != nil {
+			m.Logger.Warn("saving no-match cache failed", "err", saveErr)
+		}
+	}
+	return out, stats, err
+}
+
+// eachPending calls search for each index in pending using up to
+// Concurrency workers, waiting on the search delay before each one. Each
+// worker owns the indexes it takes off the queue, so search may write
+// profiles[i] without locking. The first search error stops the run
+// unless ContinueOnError is set, in which case failures are logged and
+// counted instead.
+func (m *Matcher) eachPending(ctx context.Context, profiles []scraper.Profile, pending []int, search func(ctx context.Context, i int) error) (failed int, err error) {
+	workers := m.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex // guards failed and firstErr
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	queue := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if err := m.searchDelay.Wait(ctx); err != nil {
+					fail(err)
+					return
+				}
+
+				if err := search(ctx, i); err != nil {
+					p := profiles[i]
+					err = fmt.Errorf("search error for %q (%s): %w", p.Name, p.ID, err)
+					if !m.ContinueOnError || ctx.Err() != nil || errors.Is(err, ErrSearchQuotaExceeded) || errors.Is(err, ErrNoResultsStreak) {
+						// Stop on first search error so the caller can
+						// persist partial results and optionally resume later.
+						fail(err)
+						return
+					}
+					m.Logger.Warn("search failed; skipping profile", "err", err)
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
 			}
-			log.Printf("linkedin: matched %q (%s) -> %s (and %d alternatives)", p.Name, p.ID, urls[0], len(urls)-1)
+		}()
+	}
+
+feed:
+	for _, i := range pending {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return failed, firstErr
+	}
+	return failed, ctx.Err()
+}
+
+// applyCandidates returns p marked as searched, with the first personal
+// profile among urls (or, with VerifyNames, the best fit for p's name) as
+// the primary URL and every other candidate in PossibleLinkedInURLs.
+func (m *Matcher) applyCandidates(p scraper.Profile, urls []string) scraper.Profile {
+	p.LinkedInSearched = true
+
+	var personal, other []string
+	for _, u := range urls {
+		if isPersonalProfileURL(u) {
+			personal = append(personal, u)
 		} else {
-			log.Printf("linkedin: no linkedin.com results for %q (%s)", p.Name, p.ID)
+			other = append(other, u)
 		}
+	}
 
-		if m.searchDelay > 0 {
-			time.Sleep(m.searchDelay)
+	eligible := personal
+	var rejected []string
+	if m.VerifyNames {
+		eligible, rejected = rankByName(p.Name, personal)
+		if len(rejected) > 0 {
+			m.Logger.Debug("candidates don't fit the name", "name", p.Name, "id", p.ID, "urls", rejected)
 		}
 	}
 
-	return out, nil
+	var primary string
+	var possible []string
+	if len(eligible) > 0 {
+		primary = eligible[0]
+		possible = append(possible, eligible[1:]...)
+	}
+	possible = append(possible, rejected...)
+	possible = append(possible, other...)
+
+	p.LinkedInURL = primary
+	if possible = dedupeCandidates(primary, possible); len(possible) > 0 {
+		p.PossibleLinkedInURLs = possible
+	}
+	return p
 }
 
-// googleSearchResponse is a minimal representation of the Google Custom Search
-// JSON API response. Adjust this if you use a different provider.
-type googleSearchResponse struct {
-	Items []struct {
-		Link string `json:"link"`
-	} `json:"items"`
+// record counts and logs the outcome of searching for p. candidates is the
+// number of URLs the search returned.
+func (m *Matcher) record(stats *EnrichmentStats, p scraper.Profile, variant string, candidates int) {
+	if variant != "" {
+		stats.MatchesByVariant[variant]++
+	}
+
+	switch {
+	case p.LinkedInURL != "":
+		stats.Matched++
+		m.Logger.Info("matched linkedin profile", "name", p.Name, "id", p.ID, "url", p.LinkedInURL, "alternatives", candidates-1)
+	case candidates > 0:
+		stats.CandidatesOnly++
+		m.Logger.Info("no /in/ profile among linkedin.com results", "name", p.Name, "id", p.ID, "candidates", candidates)
+	default:
+		stats.NoResults++
+		m.Logger.Info("no linkedin.com results", "name", p.Name, "id", p.ID)
+	}
 }
 
 // findLinkedInCandidates queries the configured search API for candidate
-// LinkedIn URLs and returns a slice of linkedin.com/in/... links in the
-// order returned by the search engine.
-func (m *Matcher) findLinkedInCandidates(ctx context.Context, p scraper.Profile) ([]string, error) {
+// LinkedIn URLs and returns the results searchLinkedIn keeps, /in/
+// profiles first and otherwise in the order returned by the search engine,
+// along with the query variant that produced them ("" when nothing
+// was found).
+func (m *Matcher) findLinkedInCandidates(ctx context.Context, p scraper.Profile) ([]Result, string, error) {
 	name := strings.TrimSpace(p.Name)
-	company := strings.TrimSpace(p.Company)
+	company := normalizeCompany(p.Company)
 
-	var queries []string
-	if name != "" && company != "" {
-		queries = append(queries, fmt.Sprintf("%q %q site:linkedin.com", name, company))
+	type query struct {
+		variant string
+		text    string
 	}
-	if name != "" {
-		queries = append(queries, fmt.Sprintf("%q site:linkedin.com", name))
-		queries = append(queries, fmt.Sprintf("%s site:linkedin.com", name))
+	var queries []query
+	switch {
+	case len(m.QueryTemplates) > 0:
+		seen := make(map[string]bool)
+		for i, t := range m.QueryTemplates {
+			text, err := renderQuery(t, p)
+			if err != nil {
+				return nil, "", err
+			}
+			if text != "" && !seen[text] {
+				seen[text] = true
+				queries = append(queries, query{queryVariant(i), text})
+			}
+		}
+	case name != "":
+		if company != "" {
+			queries = append(queries, query{VariantNameCompany, fmt.Sprintf("%q %q site:linkedin.com", name, company)})
+		}
+		queries = append(queries, query{VariantName, fmt.Sprintf("%q site:linkedin.com", name)})
+		queries = append(queries, query{VariantNameUnquoted, fmt.Sprintf("%s site:linkedin.com", name)})
 	}
 	if len(queries) == 0 {
-		return nil, nil
+		return nil, "", nil
 	}
 
-	for idx, query := range queries {
-		log.Printf("linkedin: querying for %q (%s) with variant %d: %s", p.Name, p.ID, idx+1, query)
+	for idx, q := range queries {
+		m.Logger.Debug("querying search API", "name", p.Name, "id", p.ID, "variant", q.variant, "query", q.text)
 
-		urls, err := m.searchOnce(ctx, query)
+		results, err := m.searchLinkedIn(ctx, p.Name, q.text)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		if len(urls) > 0 {
+		if len(results) > 0 {
 			if idx > 0 {
-				log.Printf("linkedin: matches for %q (%s) came from fallback query %d", p.Name, p.ID, idx+1)
+				m.Logger.Debug("matches came from fallback query", "name", p.Name, "id", p.ID, "variant", q.variant)
 			}
-			return urls, nil
+			return results, q.variant, nil
 		}
 	}
 
-	return nil, nil
+	return nil, "", nil
 }
 
-func (m *Matcher) searchOnce(ctx context.Context, query string) ([]string, error) {
+// search runs query against the search provider, honoring the search
+// timeout, Limiter, and Throttle, and returns the results in rank order,
+// unfiltered. A start > 0 asks a PagingProvider for the page of
+// results beginning at that offset.
+func (m *Matcher) search(ctx context.Context, query string, start int) ([]Result, error) {
+	if m.searchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.searchTimeout)
+		defer cancel()
+	}
 
-	u, err := url.Parse("https://www.googleapis.com/customsearch/v1")
+	var req *http.Request
+	var err error
+	if pp, ok := m.provider.(PagingProvider); ok && start > 0 {
+		req, err = pp.NewPageRequest(ctx, query, start)
+	} else {
+		req, err = m.provider.NewRequest(ctx, query)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if m.userAgent != "" {
+		req.Header.Set("User-Agent", m.userAgent)
+	}
+	decompress.Accept(req)
 
-	q := u.Query()
-	q.Set("key", m.searchAPIKey)
-	q.Set("cx", m.searchEngineID)
-	q.Set("q", query)
-	// Ask for more results to increase the chance
-	// of finding a LinkedIn URL.
-	q.Set("num", "10")
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if m.Limiter != nil {
+		if err := m.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if m.Throttle != nil {
+		if err := m.Throttle.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
+		m.count("error")
 		return nil, err
 	}
 	defer resp.Body.Close()
+	m.count(strconv.Itoa(resp.StatusCode))
+	if err := decompress.Body(resp); err != nil {
+		return nil, err
+	}
+
+	if m.Throttle != nil {
+		if pause := m.Throttle.Observe(resp.Header); pause > 0 {
+			m.Logger.Warn("search API rate limit exhausted, pausing until reset", "pause", pause)
+		}
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if quotaExceeded(resp.StatusCode, body) {
+			return nil, fmt.Errorf("%w (status %d: %s)", ErrSearchQuotaExceeded, resp.StatusCode, googleErrorMessage(body))
+		}
 		return nil, fmt.Errorf("search status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	var sr googleSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
-		return nil, err
+	results, err := m.provider.ParseResults(bodylimit.Wrap(resp.Body, m.maxBody))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s results: %w", m.provider.Name(), err)
+	}
+	return results, nil
+}
+
+// count records a search request with the given status on Metrics.
+func (m *Matcher) count(status string) {
+	if m.Metrics != nil {
+		m.Metrics.Inc(metrics.SearchQueries, "provider", m.provider.Name(), "status", status)
 	}
+}
+
+// searchLinkedIn runs query and returns the results with personal
+// linkedin.com links, normalized, /in/ profiles first, then legacy /pub/
+// ones, then with IncludeCompanyPages company pages. With a PagingProvider it
+// reads further pages, waiting on the search delay before each, until
+// maxResults results have been seen, the results run out, or a confident
+// match for name turns up.
+func (m *Matcher) searchLinkedIn(ctx context.Context, name, query string) ([]Result, error) {
+	var personal, legacy, company []Result
+	var personalURLs []string
+	seen := make(map[string]bool)
+
+	for start := 0; ; {
+		results, err := m.search(ctx, query, start)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			link := normalizeLinkedInURL(result.URL)
+			if link == "" || seen[link] {
+				continue
+			}
+			seen[link] = true
+			result.URL = link
+			switch kind := classifyLinkedInURL(link); {
+			case kind == kindPersonal:
+				personal = append(personal, result)
+				personalURLs = append(personalURLs, link)
+			case kind == kindLegacyPersonal:
+				legacy = append(legacy, result)
+			case kind == kindCompany && m.IncludeCompanyPages:
+				company = append(company, result)
+			default:
+				m.Logger.Debug("dropping non-profile linkedin result", "name", name, "url", link)
+			}
+		}
 
-	var personal []string
-	var other []string
-	for _, item := range sr.Items {
-		link := strings.TrimSpace(item.Link)
-		if link == "" {
-			continue
+		start += len(results)
+		pp, ok := m.provider.(PagingProvider)
+		if !ok || len(results) < pp.PageSize() || start >= m.maxResults || m.confident(name, personalURLs) {
+			break
 		}
-		if strings.Contains(link, "linkedin.com/in/") {
-			personal = append(personal, link)
-		} else if strings.Contains(link, "linkedin.com/") {
-			other = append(other, link)
+		m.Logger.Debug("fetching next page of search results", "name", name, "query", query, "start", start)
+		if err := m.searchDelay.Wait(ctx); err != nil {
+			return nil, err
 		}
 	}
-	// Prefer personal profile URLs (/in/), but fall back
-	// to any linkedin.com URLs if that's all we have.
-	return append(personal, other...), nil
+
+	return slices.Concat(personal, legacy, company), nil
+}
+
+// confident reports whether personal holds a profile URL that
+// applyCandidates would make name's primary LinkedInURL, so later pages of
+// results needn't be searched.
+func (m *Matcher) confident(name string, personal []string) bool {
+	if !m.VerifyNames {
+		return len(personal) > 0
+	}
+	ranked, _ := rankByName(name, personal)
+	return len(ranked) > 0
 }
@@ -0,0 +1,75 @@
+package linkedin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"bitcoinconferencescraper/internal/config"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+func TestEnrichmentStatsString(t *testing.T) {
+	tests := []struct {
+		stats EnrichmentStats
+		want  string
+	}{
+		{
+			EnrichmentStats{AlreadyLinked: 1, PreviouslySearched: 2, NoName: 3, Matched: 4, CandidatesOnly: 5, NoResults: 6},
+			"1 already linked, 2 previously searched, 3 without a name, 4 matched, 5 candidates only, 6 no results",
+		},
+		{
+			EnrichmentStats{PreviouslySearched: 2, RecentNoMatch: 7, HasCandidates: 8, Matched: 4, Backfilled: 1, Failed: 9,
+				MatchesByVariant: map[string]int{VariantName: 1, VariantNameCompany: 3}},
+			"0 already linked, 2 previously searched, 7 recently without results, 8 skipped with candidates, 0 without a name, 4 matched (1 backfilled), 0 candidates only (" +
+				VariantNameCompany + " 3, " + VariantName + " 1), 0 no results, 9 failed",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.stats.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// countingProvider fails every search, counting the attempts.
+type countingProvider struct{ searches *int }
+
+func (countingProvider) Name() string { return "counting" }
+
+func (p countingProvider) NewRequest(ctx context.Context, query string) (*http.Request, error) {
+	*p.searches++
+	return nil, errors.New("search called")
+}
+
+func (countingProvider) ParseResults(io.Reader) ([]Result, error) { return nil, nil }
+
+func TestEnrichProfilesSkipsCandidatesByDefault(t *testing.T) {
+	profiles := []scraper.Profile{
+		{ID: "a1", Name: "Ada Lovelace", PossibleLinkedInURLs: []string{"https://www.linkedin.com/in/ada"}},
+	}
+	newMatcher := func(searches *int) *Matcher {
+		m := NewMatcher(nil, config.Config{SearchProvider: config.SearchProviderDuckDuckGo})
+		m.provider = countingProvider{searches}
+		m.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		return m
+	}
+
+	var searches int
+	_, stats, err := newMatcher(&searches).EnrichProfiles(context.Background(), profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searches != 0 || stats.HasCandidates != 1 {
+		t.Errorf("default: %d searches, HasCandidates %d; want 0 and 1", searches, stats.HasCandidates)
+	}
+
+	m := newMatcher(&searches)
+	m.SearchCandidates = true
+	if _, stats, _ = m.EnrichProfiles(context.Background(), profiles); searches != 1 || stats.HasCandidates != 0 {
+		t.Errorf("SearchCandidates: %d searches, HasCandidates %d; want 1 and 0", searches, stats.HasCandidates)
+	}
+}
@@ -0,0 +1,93 @@
+package linkedin
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"bitcoinconferencescraper/internal/fold"
+)
+
+// nameScore rates how well the slug of a personal profile URL (as returned
+// by normalizeLinkedInURL) fits a person's name, from 0 (no part of the
+// name appears in the slug) to 1 (every part does). Both sides are folded
+// to unaccented lowercase letters first, apostrophes are dropped and
+// hyphens split words, so "José O'Brien-García" fits
+// /in/jose-obrien-garcia. Single-letter name parts such as middle initials
+// are ignored, and numeric suffixes LinkedIn adds to slugs don't count
+// against a match. A name with nothing left to compare scores 1.
+func nameScore(name, profileURL string) float64 {
+	parts := nameTokens(name)
+	if len(parts) == 0 {
+		return 1
+	}
+
+	slug := slugTokens(profileURL)
+	inSlug := make(map[string]bool, len(slug))
+	for _, t := range slug {
+		inSlug[t] = true
+	}
+	// Slugs often run names together ("jsmith", "joseobrien").
+	joined := strings.Join(slug, "")
+
+	matched := 0
+	for _, p := range parts {
+		if inSlug[p] || (len(p) >= 3 && strings.Contains(joined, p)) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(parts))
+}
+
+// rankByName stably sorts personal profile URLs by descending nameScore
+// for name and drops those that score 0, which belong to someone else.
+func rankByName(name string, urls []string) (ranked, rejected []string) {
+	scores := make(map[string]float64, len(urls))
+	for _, u := range urls {
+		if s := nameScore(name, u); s > 0 {
+			scores[u] = s
+			ranked = append(ranked, u)
+		} else {
+			rejected = append(rejected, u)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+	return ranked, rejected
+}
+
+// nameTokens splits name into folded words of at least two letters.
+func nameTokens(name string) []string {
+	var out []string
+	for _, w := range fold.Words(name) {
+		if len(w) >= 2 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// slugTokens returns the folded words of a profile URL's /in/ slug without
+// LinkedIn's numeric disambiguation suffixes.
+func slugTokens(profileURL string) []string {
+	slug, ok := strings.CutPrefix(profileURL, "https://www.linkedin.com/in/")
+	if !ok {
+		return nil
+	}
+	if s, err := url.PathUnescape(slug); err == nil {
+		slug = s
+	}
+
+	var out []string
+	for _, w := range strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		// "johnsmith123" keeps its name; a mixed ID like "4a1b2c" goes.
+		w = strings.TrimRightFunc(w, unicode.IsDigit)
+		if w == "" || strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+			continue
+		}
+		out = append(out, fold.Words(w)...)
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package linkedin
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNameScore(t *testing.T) {
+	tests := []struct {
+		name, url string
+		want      float64
+	}{
+		{"José O'Brien-García", "https://www.linkedin.com/in/jose-obrien-garcia", 1},
+		{"José O'Brien-García", "https://www.linkedin.com/in/jos%C3%A9-obrien-garc%C3%ADa", 1},
+		{"José O'Brien-García", "https://www.linkedin.com/in/joseobriengarcia", 1},
+		{"José O'Brien-García", "https://www.linkedin.com/in/jose-garcia-4a1b2c", 2.0 / 3},
+		{"José O'Brien-García", "https://www.linkedin.com/in/john-smith", 0},
+		{"John Smith", "https://www.linkedin.com/in/john-smith-123", 1},
+		{"John Smith", "https://www.linkedin.com/in/johnsmith123", 1},
+		{"John Smith", "https://www.linkedin.com/in/jsmith", 0.5},
+		{"John Smith", "https://www.linkedin.com/in/john-doe", 0.5},
+		{"John Smith", "https://www.linkedin.com/in/jane-doe", 0},
+		// Middle initials don't count either way.
+		{"John Q. Smith", "https://www.linkedin.com/in/john-smith", 1},
+		{"John Smith", "https://www.linkedin.com/in/john-q-smith", 1},
+		// Accents on the slug side, and apostrophes and hyphens.
+		{"Zoe Muller", "https://www.linkedin.com/in/zo%C3%AB-m%C3%BCller", 1},
+		{"Mary-Jane O’Neil", "https://www.linkedin.com/in/mary_jane.oneil", 1},
+		// Nothing to compare.
+		{"", "https://www.linkedin.com/in/anyone", 1},
+		{"J.", "https://www.linkedin.com/in/anyone", 1},
+		{"John Smith", "https://www.linkedin.com/company/smith", 0},
+	}
+	for _, tt := range tests {
+		if got := nameScore(tt.name, tt.url); got != tt.want {
+			t.Errorf("nameScore(%q, %q) = %v, want %v", tt.name, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRankByName(t *testing.T) {
+	urls := []string{
+		"https://www.linkedin.com/in/john-doe",
+		"https://www.linkedin.com/in/jane-roe",
+		"https://www.linkedin.com/in/john-smith-9",
+		"https://www.linkedin.com/in/smith-john",
+	}
+	ranked, rejected := rankByName("John Smith", urls)
+	if want := []string{urls[2], urls[3], urls[0]}; !slices.Equal(ranked, want) {
+		t.Errorf("ranked %v, want %v", ranked, want)
+	}
+	if want := []string{urls[1]}; !slices.Equal(rejected, want) {
+		t.Errorf("rejected %v, want %v", rejected, want)
+	}
+}
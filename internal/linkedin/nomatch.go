@@ -0,0 +1,127 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+)
+
+// NoMatchCache remembers which profiles a LinkedIn search recently found
+// nothing for, across runs, so EnrichProfiles doesn't spend quota
+// searching for them again until TTL has passed. Entries are keyed by
+// profile ID. It is safe for concurrent use.
+type NoMatchCache struct {
+	path string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]time.Time // profile ID -> when search found nothing
+	dirty   bool
+}
+
+// LoadNoMatchCache reads the cache file at path, which need not exist yet.
+// Entries older than ttl are ignored and dropped on Save; ttl <= 0 keeps
+// them forever.
+func LoadNoMatchCache(path string, ttl time.Duration) (*NoMatchCache, error) {
+	c := &NoMatchCache{path: path, ttl: ttl, entries: make(map[string]time.Time)}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		return nil, fmt.Errorf("decoding no-match cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Recent reports whether a search for the profile with the given ID found
+// nothing within the TTL.
+func (c *NoMatchCache) Recent(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, ok := c.entries[id]
+	return ok && c.fresh(at, time.Now())
+}
+
+// Record notes whether the search for the profile with the given ID found
+// anything: a match removes any entry, no match (re)starts its TTL.
+func (c *NoMatchCache) Record(id string, matched bool) {
+	if id == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if matched {
+		if _, ok := c.entries[id]; ok {
+			delete(c.entries, id)
+			c.dirty = true
+		}
+		return
+	}
+	c.entries[id] = time.Now().UTC()
+	c.dirty = true
+}
+
+// Forget drops any entry for the profile with the given ID, as if it had
+// never been searched.
+func (c *NoMatchCache) Forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; ok {
+		delete(c.entries, id)
+		c.dirty = true
+	}
+}
+
+// Save writes the cache back to its file, without expired entries, if
+// anything was recorded since it was loaded.
+func (c *NoMatchCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	now := time.Now()
+	for id, at := range c.entries {
+		if !c.fresh(at, now) {
+			delete(c.entries, id)
+		}
+	}
+
+	f, err := atomicfile.Create(c.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c.entries); err != nil {
+		return err
+	}
+	if err := f.Commit(); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+func (c *NoMatchCache) fresh(at, now time.Time) bool {
+	return c.ttl <= 0 || now.Sub(at) < c.ttl
+}
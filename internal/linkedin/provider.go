@@ -0,0 +1,279 @@
+package linkedin
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SearchProvider adapts a web search backend for the Matcher. The Matcher
+// sends the request itself, so rate limits, timeouts and throttling apply
+// the same way to every provider.
+type SearchProvider interface {
+	// Name identifies the provider in logs.
+	Name() string
+
+	// NewRequest builds the request that searches for query.
+	NewRequest(ctx context.Context, query string) (*http.Request, error)
+
+	// ParseResults extracts the results from a 200 response body, in rank
+	// order. Links need not be normalized or filtered to LinkedIn.
+	ParseResults(body io.Reader) ([]Result, error)
+}
+
+// Result is one search result.
+type Result struct {
+	// URL is the result's link.
+	URL string
+	// Title and Snippet are the result's title and description as plain
+	// text, or "" if the provider didn't give them.
+	Title   string
+	Snippet string
+}
+
+// PagingProvider is a SearchProvider that can fetch results past the
+// first page.
+type PagingProvider interface {
+	SearchProvider
+
+	// PageSize is the number of results in a full page. A shorter page is
+	// the last one.
+	PageSize() int
+
+	// NewPageRequest builds the request for the page of query's results
+	// that starts at the given 0-based offset.
+	NewPageRequest(ctx context.Context, query string, start int) (*http.Request, error)
+}
+
+// googlePageSize is the most results the Custom Search API returns per
+// request.
+const googlePageSize = 10
+
+// GoogleProvider searches with the Google Custom Search JSON API.
+type GoogleProvider struct {
+	APIKey   string
+	EngineID string
+}
+
+// Name implements SearchProvider.
+func (GoogleProvider) Name() string { return "google" }
+
+// NewRequest implements SearchProvider.
+func (g GoogleProvider) NewRequest(ctx context.Context, query string) (*http.Request, error) {
+	return g.NewPageRequest(ctx, query, 0)
+}
+
+// PageSize implements PagingProvider.
+func (GoogleProvider) PageSize() int { return googlePageSize }
+
+// NewPageRequest implements PagingProvider. Google serves at most the
+// first 100 results of a query.
+func (g GoogleProvider) NewPageRequest(ctx context.Context, query string, start int) (*http.Request, error) {
+	u, err := url.Parse("https://www.googleapis.com/customsearch/v1")
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	q.Set("key", g.APIKey)
+	q.Set("cx", g.EngineID)
+	q.Set("q", query)
+	// Ask for more results to increase the chance
+	// of finding a LinkedIn URL.
+	q.Set("num", strconv.Itoa(googlePageSize))
+	if start > 0 {
+		// start is 1-based.
+		q.Set("start", strconv.Itoa(start+1))
+	}
+	u.RawQuery = q.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+}
+
+// googleSearchResponse is a minimal representation of the Google Custom Search
+// JSON API response.
+type googleSearchResponse struct {
+	Items []struct {
+		Link    string `json:"link"`
+		Title   string `json:"title"`
+		Snippet string `json:"snippet"`
+	} `json:"items"`
+}
+
+// googleErrorResponse is the error body Google APIs send with a non-200
+// status.
+type googleErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// googleQuotaReasons are the error.errors[].reason values Google uses when
+// a quota is used up.
+var googleQuotaReasons = map[string]bool{
+	"dailyLimitExceeded":    true,
+	"quotaExceeded":         true,
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+}
+
+// quotaExceeded reports whether a non-200 search response means the quota
+// is used up: any 429, or a 403 whose Google error body gives a quota
+// reason.
+func quotaExceeded(status int, body []byte) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if status != http.StatusForbidden {
+		return false
+	}
+
+	var er googleErrorResponse
+	if json.Unmarshal(body, &er) != nil {
+		return false
+	}
+	if er.Error.Status == "RESOURCE_EXHAUSTED" {
+		return true
+	}
+	for _, e := range er.Error.Errors {
+		if googleQuotaReasons[e.Reason] {
+			return true
+		}
+	}
+	return false
+}
+
+// googleErrorMessage returns error.message from a Google error body, or
+// the trimmed body itself if it isn't one.
+func googleErrorMessage(body []byte) string {
+	var er googleErrorResponse
+	if json.Unmarshal(body, &er) == nil && er.Error.Message != "" {
+		return er.Error.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// ParseResults implements SearchProvider.
+func (GoogleProvider) ParseResults(body io.Reader) ([]Result, error) {
+	var sr googleSearchResponse
+	if err := json.NewDecoder(body).Decode(&sr); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(sr.Items))
+	for _, item := range sr.Items {
+		results = append(results, Result{URL: item.Link, Title: item.Title, Snippet: item.Snippet})
+	}
+	return results, nil
+}
+
+// DuckDuckGoProvider scrapes DuckDuckGo's JavaScript-free HTML results page.
+// It needs no API key, which makes it a fallback for events too large for
+// Google's free quota.
+//
+// Automated queries are against DuckDuckGo's terms of service and it
+// blocks clients that search too quickly, so use a generous search delay
+// (several seconds) and only use this where you are comfortable with that.
+type DuckDuckGoProvider struct {
+	// UserAgent is sent with every request. DuckDuckGo rejects obvious
+	// bots, so it defaults to a current desktop browser's.
+	UserAgent string
+}
+
+// duckDuckGoUserAgent is the default DuckDuckGoProvider.UserAgent.
+const duckDuckGoUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
+// Name implements SearchProvider.
+func (DuckDuckGoProvider) Name() string { return "duckduckgo" }
+
+// NewRequest implements SearchProvider.
+func (d DuckDuckGoProvider) NewRequest(ctx context.Context, query string) (*http.Request, error) {
+	u := "https://html.duckduckgo.com/html/?" + url.Values{"q": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ua := d.UserAgent
+	if ua == "" {
+		ua = duckDuckGoUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set("Accept", "text/html")
+	return req, nil
+}
+
+var (
+	anchor    = regexp.MustCompile(`(?is)(<a\s[^>]*>)(.*?)</a>`)
+	attrValue = regexp.MustCompile(`(?is)\b(class|href)\s*=\s*"([^"]*)"`)
+	anyTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// ParseResults implements SearchProvider. It collects the href and text of
+// every result title link (class "result__a"), unwrapping DuckDuckGo's
+// /l/?uddg= redirect links, and the text of the snippet link (class
+// "result__snippet") that follows each.
+func (DuckDuckGoProvider) ParseResults(body io.Reader) ([]Result, error) {
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, m := range anchor.FindAllSubmatch(page, -1) {
+		var class, href string
+		for _, attr := range attrValue.FindAllSubmatch(m[1], -1) {
+			switch strings.ToLower(string(attr[1])) {
+			case "class":
+				class = string(attr[2])
+			case "href":
+				href = html.UnescapeString(string(attr[2]))
+			}
+		}
+		switch {
+		case href != "" && hasClass(class, "result__a"):
+			results = append(results, Result{URL: unwrapDuckDuckGoLink(href), Title: htmlText(m[2])})
+		case hasClass(class, "result__snippet") && len(results) > 0:
+			results[len(results)-1].Snippet = htmlText(m[2])
+		}
+	}
+	return results, nil
+}
+
+// htmlText returns the text of an HTML fragment with tags removed,
+// entities decoded, and runs of space collapsed.
+func htmlText(fragment []byte) string {
+	return strings.Join(strings.Fields(html.UnescapeString(anyTag.ReplaceAllString(string(fragment), ""))), " ")
+}
+
+// unwrapDuckDuckGoLink returns the target of a //duckduckgo.com/l/?uddg=
+// redirect, or href itself for a direct link.
+func unwrapDuckDuckGoLink(href string) string {
+	u, err := url.Parse(href)
+	if err != nil || !strings.HasSuffix(u.Host, "duckduckgo.com") || u.Path != "/l/" {
+		return href
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	return href
+}
+
+func hasClass(classes, name string) bool {
+	for _, c := range strings.Fields(classes) {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
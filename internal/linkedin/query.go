@@ -0,0 +1,67 @@
+package linkedin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// QueryData is what a query template is executed with: the profile's
+// fields, plus its company in the form the built-in queries search for.
+type QueryData struct {
+	scraper.Profile
+
+	// CleanCompany is Company lowercased, with legal suffixes and stray
+	// punctuation removed ("ACME, Inc." becomes "acme"), as the built-in
+	// queries use it.
+	CleanCompany string
+}
+
+// queryFuncs are the functions available in query templates.
+var queryFuncs = template.FuncMap{
+	// quote wraps s in double quotes for an exact-phrase search.
+	"quote": strconv.Quote,
+}
+
+// ParseQueryTemplates parses LinkedIn search query templates for
+// Matcher.QueryTemplates. Each is a text/template executed with a
+// QueryData, such as
+//
+//	{{quote .Name}} {{quote .CleanCompany}} {{.Location}} site:linkedin.com/in
+//
+// and has the quote function for exact phrases. Templates are tried out on
+// an empty profile so references to unknown fields fail here rather than
+// mid-run.
+func ParseQueryTemplates(texts []string) ([]*template.Template, error) {
+	var tmpls []*template.Template
+	for i, text := range texts {
+		t, err := template.New(queryVariant(i)).Funcs(queryFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("query template %d: %w", i+1, err)
+		}
+		if _, err := renderQuery(t, scraper.Profile{}); err != nil {
+			return nil, err
+		}
+		tmpls = append(tmpls, t)
+	}
+	return tmpls, nil
+}
+
+// queryVariant names the EnrichmentStats variant of the i'th query
+// template.
+func queryVariant(i int) string {
+	return "template-" + strconv.Itoa(i+1)
+}
+
+// renderQuery executes t for p and returns the query with runs of space
+// collapsed, so optional parts left out with {{if}} leave no gaps.
+func renderQuery(t *template.Template, p scraper.Profile) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, QueryData{Profile: p, CleanCompany: normalizeCompany(p.Company)}); err != nil {
+		return "", fmt.Errorf("query %s: %w", t.Name(), err)
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
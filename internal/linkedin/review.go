@@ -0,0 +1,122 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// closeMargin is how far below the best name score a candidate may fit a
+// profile's name and still count as close to it.
+const closeMargin = 0.25
+
+// CloseCandidates returns the LinkedIn URLs of p, its LinkedInURL and
+// PossibleLinkedInURLs, that fit its name about as well as the best of them
+// (see nameScore), best first. It returns nil unless there are at least
+// two, since then the pick isn't ambiguous.
+func CloseCandidates(p scraper.Profile) []string {
+	urls := p.PossibleLinkedInURLs
+	if p.LinkedInURL != "" {
+		urls = append([]string{p.LinkedInURL}, urls...)
+	}
+	ranked, _ := rankByName(p.Name, dedupeCandidates("", urls))
+	if len(ranked) < 2 {
+		return nil
+	}
+
+	best := nameScore(p.Name, ranked[0])
+	out := ranked[:1]
+	for _, u := range ranked[1:] {
+		if nameScore(p.Name, u) >= best-closeMargin {
+			out = append(out, u)
+		}
+	}
+	if len(out) < 2 {
+		return nil
+	}
+	return out
+}
+
+// ChooseLinkedIn returns p with url as its LinkedInURL and the URL it had
+// before moved among its PossibleLinkedInURLs. The recorded LinkedInStatus
+// is dropped if the URL changes, since it was checked for the old one.
+func ChooseLinkedIn(p scraper.Profile, url string) scraper.Profile {
+	if normalizeLinkedInURL(url) == normalizeLinkedInURL(p.LinkedInURL) {
+		return p
+	}
+	p.PossibleLinkedInURLs = dedupeCandidates(url, append(p.PossibleLinkedInURLs, p.LinkedInURL))
+	p.LinkedInURL = url
+	p.LinkedInStatus = 0
+	return p
+}
+
+// ReviewDecisions remembers the LinkedIn URL picked by hand for profiles
+// with several close candidates, keyed by profile ID, so later runs apply
+// the pick instead of asking again. An empty URL records that the profile
+// was skipped.
+type ReviewDecisions struct {
+	path    string
+	entries map[string]string // profile ID -> chosen URL, "" if skipped
+	dirty   bool
+}
+
+// LoadReviewDecisions reads the decisions file at path, which need not
+// exist yet.
+func LoadReviewDecisions(path string) (*ReviewDecisions, error) {
+	d := &ReviewDecisions{path: path, entries: make(map[string]string)}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &d.entries); err != nil {
+		return nil, fmt.Errorf("decoding review decisions %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// Get returns the URL chosen for the profile with the given ID, and
+// whether it was reviewed at all.
+func (d *ReviewDecisions) Get(id string) (url string, ok bool) {
+	url, ok = d.entries[id]
+	return url, ok
+}
+
+// Set records url as chosen for the profile with the given ID; "" records
+// a skip.
+func (d *ReviewDecisions) Set(id, url string) {
+	d.entries[id] = url
+	d.dirty = true
+}
+
+// Save writes the decisions back to their file if any were set.
+func (d *ReviewDecisions) Save() error {
+	if !d.dirty {
+		return nil
+	}
+
+	f, err := atomicfile.Create(d.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d.entries); err != nil {
+		return err
+	}
+	if err := f.Commit(); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
@@ -0,0 +1,170 @@
+package linkedin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// TwitterStats tallies what EnrichTwitter did with each profile.
+type TwitterStats struct {
+	// AlreadySet counts profiles that had a Twitter account on input.
+	AlreadySet int
+	// PreviouslySearched counts profiles skipped because an earlier run
+	// already searched for them.
+	PreviouslySearched int
+	// NoName counts profiles skipped because they have no name to search.
+	NoName int
+
+	// Matched counts profiles that got a Twitter account from search.
+	Matched int
+	// NoResults counts profiles for which search found no account.
+	NoResults int
+	// Failed counts profiles whose search failed with ContinueOnError set.
+	Failed int
+}
+
+// String formats s as a one-line summary.
+func (s TwitterStats) String() string {
+	failed := ""
+	if s.Failed > 0 {
+		failed = fmt.Sprintf(", %d failed", s.Failed)
+	}
+	return fmt.Sprintf("%d already set, %d previously searched, %d without a name, %d matched, %d no results%s",
+		s.AlreadySet, s.PreviouslySearched, s.NoName, s.Matched, s.NoResults, failed)
+}
+
+// twitterSites restricts a query to Twitter and X profiles.
+const twitterSites = "(site:twitter.com OR site:x.com)"
+
+// EnrichTwitter fills in the Twitter field of profiles that lack one and
+// haven't been searched before (TwitterSearched is false). It queries
+// `"Name" "Company" (site:twitter.com OR site:x.com)`, falling back to the
+// name alone, and keeps the first result that points at an account, as
+// https://twitter.com/<handle>.
+//
+// Searches share the provider, search delay, Limiter, Throttle, and
+// Concurrency with EnrichProfiles, and errors are handled the same way.
+func (m *Matcher) EnrichTwitter(ctx context.Context, profiles []scraper.Profile) ([]scraper.Profile, TwitterStats, error) {
+	var stats TwitterStats
+
+	if !m.enabled {
+		m.Logger.Info("search API not configured; skipping Twitter enrichment")
+		return profiles, stats, nil
+	}
+
+	out := make([]scraper.Profile, len(profiles))
+	copy(out, profiles)
+
+	var pending []int
+	for i, p := range out {
+		switch {
+		case p.Twitter != "":
+			stats.AlreadySet++
+		case p.TwitterSearched:
+			stats.PreviouslySearched++
+		case strings.TrimSpace(p.Name) == "":
+			stats.NoName++
+		default:
+			pending = append(pending, i)
+		}
+	}
+
+	var mu sync.Mutex // guards stats
+	failed, err := m.eachPending(ctx, out, pending, func(ctx context.Context, i int) error {
+		p := out[i]
+		account, err := m.findTwitterAccount(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		p.TwitterSearched = true
+		p.Twitter = account
+		out[i] = p
+
+		mu.Lock()
+		defer mu.Unlock()
+		if account != "" {
+			stats.Matched++
+			m.Logger.Info("matched twitter account", "name", p.Name, "id", p.ID, "url", account)
+		} else {
+			stats.NoResults++
+			m.Logger.Info("no twitter results", "name", p.Name, "id", p.ID)
+		}
+		return nil
+	})
+	stats.Failed = failed
+	return out, stats, err
+}
+
+// findTwitterAccount searches for p's Twitter account and returns its
+// canonical URL, or "" if no result points at one.
+func (m *Matcher) findTwitterAccount(ctx context.Context, p scraper.Profile) (string, error) {
+	name := strings.TrimSpace(p.Name)
+	company := normalizeCompany(p.Company)
+
+	var queries []string
+	if company != "" {
+		queries = append(queries, fmt.Sprintf("%q %q %s", name, company, twitterSites))
+	}
+	queries = append(queries, fmt.Sprintf("%q %s", name, twitterSites))
+
+	for _, q := range queries {
+		m.Logger.Debug("querying search API", "name", p.Name, "id", p.ID, "query", q)
+
+		results, err := m.search(ctx, q, 0)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range results {
+			if account := twitterAccountURL(r.URL); account != "" {
+				return account, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// twitterReservedPaths are first path segments on twitter.com and x.com
+// that are site pages rather than accounts.
+var twitterReservedPaths = map[string]bool{
+	"about":    true,
+	"explore":  true,
+	"hashtag":  true,
+	"home":     true,
+	"i":        true,
+	"intent":   true,
+	"login":    true,
+	"messages": true,
+	"privacy":  true,
+	"search":   true,
+	"settings": true,
+	"share":    true,
+	"signup":   true,
+	"tos":      true,
+}
+
+// twitterAccountURL returns the canonical account URL for a search result
+// on twitter.com or x.com, or "" if the result isn't on either site or
+// points at a site page.
+func twitterAccountURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "mobile.")
+	if host != "twitter.com" && host != "x.com" {
+		return ""
+	}
+
+	first, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if twitterReservedPaths[strings.ToLower(first)] {
+		return ""
+	}
+	return scraper.NormalizeTwitter(raw)
+}
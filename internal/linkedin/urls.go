@@ -0,0 +1,106 @@
+package linkedin
+
+import (
+	"net/url"
+	"strings"
+)
+
+// normalizeLinkedInURL canonicalizes a LinkedIn URL so the same profile
+// always produces the same string: https, host www.linkedin.com (country
+// and mobile subdomains such as de. or uk. are folded in), no query string
+// or fragment (dropping ?trk= and similar tracking), and a lowercase path
+// without a trailing slash. Personal profile URLs are cut down to
+// /in/<slug>, dropping locale or section suffixes. It returns "" if raw is
+// not a linkedin.com URL.
+func normalizeLinkedInURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return ""
+	}
+
+	path := strings.ToLower(strings.Trim(u.Path, "/"))
+	if slug, ok := strings.CutPrefix(path, "in/"); ok {
+		slug, _, _ = strings.Cut(slug, "/")
+		path = "in/" + slug
+	}
+
+	out := url.URL{Scheme: "https", Host: "www.linkedin.com", Path: "/" + path}
+	return strings.TrimSuffix(out.String(), "/")
+}
+
+// dedupeCandidates returns possible normalized, without repeats, empty
+// entries, or primary, keeping the first occurrence of each URL. Entries
+// normalizeLinkedInURL rejects are kept as they are. It returns nil if
+// nothing is left.
+func dedupeCandidates(primary string, possible []string) []string {
+	seen := make(map[string]bool, len(possible)+1)
+	if u := normalizeLinkedInURL(primary); u != "" {
+		seen[u] = true
+	}
+
+	var out []string
+	for _, raw := range possible {
+		u := normalizeLinkedInURL(raw)
+		if u == "" {
+			u = strings.TrimSpace(raw)
+		}
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		out = append(out, u)
+	}
+	return out
+}
+
+// urlKind is what a normalized linkedin.com URL points at.
+type urlKind int
+
+const (
+	kindOther          urlKind = iota // posts, jobs, search pages, ...
+	kindPersonal                      // a person's /in/ profile
+	kindLegacyPersonal                // a person's old /pub/ profile, which LinkedIn redirects to /in/
+	kindCompany                       // an organization's /company/, /showcase/, or /school/ page
+)
+
+// classifyLinkedInURL returns the kind of page u, as returned by
+// normalizeLinkedInURL, points at.
+func classifyLinkedInURL(u string) urlKind {
+	path, ok := strings.CutPrefix(u, "https://www.linkedin.com/")
+	if !ok {
+		return kindOther
+	}
+	section, rest, _ := strings.Cut(path, "/")
+	if rest == "" {
+		return kindOther
+	}
+	switch section {
+	case "in":
+		return kindPersonal
+	case "pub":
+		return kindLegacyPersonal
+	case "company", "showcase", "school":
+		return kindCompany
+	default:
+		return kindOther
+	}
+}
+
+// isPersonalProfileURL reports whether u, as returned by
+// normalizeLinkedInURL, points at a person's /in/ profile.
+func isPersonalProfileURL(u string) bool {
+	return classifyLinkedInURL(u) == kindPersonal
+}
@@ -0,0 +1,64 @@
+package linkedin
+
+import "testing"
+
+func TestNormalizeLinkedInURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://www.linkedin.com/in/ada-lovelace", "https://www.linkedin.com/in/ada-lovelace"},
+		// Tracking parameters and fragments.
+		{"https://www.linkedin.com/in/ada-lovelace?trk=public_profile_browsemap", "https://www.linkedin.com/in/ada-lovelace"},
+		{"https://www.linkedin.com/in/ada-lovelace/?originalSubdomain=uk&utm_source=share#experience", "https://www.linkedin.com/in/ada-lovelace"},
+		// Locale and mobile subdomains, bare hosts, and missing schemes.
+		{"https://de.linkedin.com/in/ada-lovelace", "https://www.linkedin.com/in/ada-lovelace"},
+		{"http://uk.linkedin.com/in/ada-lovelace", "https://www.linkedin.com/in/ada-lovelace"},
+		{"https://m.linkedin.com/in/ada-lovelace", "https://www.linkedin.com/in/ada-lovelace"},
+		{"linkedin.com/in/ada-lovelace", "https://www.linkedin.com/in/ada-lovelace"},
+		{"  www.LinkedIn.com/in/Ada-Lovelace  ", "https://www.linkedin.com/in/ada-lovelace"},
+		// Trailing slashes and locale or section suffixes.
+		{"https://www.linkedin.com/in/ada-lovelace/", "https://www.linkedin.com/in/ada-lovelace"},
+		{"https://www.linkedin.com/in/ada-lovelace//", "https://www.linkedin.com/in/ada-lovelace"},
+		{"https://www.linkedin.com/in/ada-lovelace/de", "https://www.linkedin.com/in/ada-lovelace"},
+		{"https://www.linkedin.com/in/ada-lovelace/details/experience/", "https://www.linkedin.com/in/ada-lovelace"},
+		// Other paths are normalized the same way but kept as they are.
+		{"https://www.linkedin.com/company/acme/?trk=x", "https://www.linkedin.com/company/acme"},
+		{"https://de.linkedin.com/pub/ada-lovelace/12/345/678", "https://www.linkedin.com/pub/ada-lovelace/12/345/678"},
+		{"https://www.linkedin.com/posts/ada_activity-123", "https://www.linkedin.com/posts/ada_activity-123"},
+		{"https://www.linkedin.com/", "https://www.linkedin.com"},
+		// Not LinkedIn at all.
+		{"https://notlinkedin.com/in/ada", ""},
+		{"https://linkedin.com.evil.example/in/ada", ""},
+		{"https://twitter.com/ada", ""},
+		{"", ""},
+		{"   ", ""},
+		{"https://www.linkedin.com/in/%zz", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeLinkedInURL(tt.in); got != tt.want {
+			t.Errorf("normalizeLinkedInURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyLinkedInURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want urlKind
+	}{
+		{"https://www.linkedin.com/in/ada-lovelace", kindPersonal},
+		{"https://www.linkedin.com/pub/ada-lovelace/12/345/678", kindLegacyPersonal},
+		{"https://www.linkedin.com/company/acme", kindCompany},
+		{"https://www.linkedin.com/school/oxford", kindCompany},
+		{"https://www.linkedin.com/showcase/acme-labs", kindCompany},
+		{"https://www.linkedin.com/posts/ada_activity-123", kindOther},
+		{"https://www.linkedin.com/jobs/view/123", kindOther},
+		{"https://www.linkedin.com/in", kindOther},
+		{"https://www.linkedin.com", kindOther},
+	}
+	for _, tt := range tests {
+		if got := classifyLinkedInURL(normalizeLinkedInURL(tt.in)); got != tt.want {
+			t.Errorf("classifyLinkedInURL(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package linkedin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bitcoinconferencescraper/internal/jitter"
+	"bitcoinconferencescraper/internal/metrics"
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// VerifyStats tallies what VerifyURLs did with each linked profile.
+type VerifyStats struct {
+	// PreviouslyVerified counts profiles skipped because their LinkedInURL
+	// already has a LinkedInStatus.
+	PreviouslyVerified int
+
+	// Reachable counts profiles whose LinkedInURL answered with a 2xx
+	// status.
+	Reachable int
+	// Unverifiable counts profiles whose LinkedInURL gave neither a 2xx
+	// nor a not-found answer, typically LinkedIn's login wall or its 999
+	// bot response. Their URLs are kept.
+	Unverifiable int
+	// Demoted counts dead URLs (404 or 410) moved from LinkedInURL to the
+	// end of PossibleLinkedInURLs.
+	Demoted int
+	// Failed counts profiles whose check failed with a network error; they
+	// are left unverified so a rerun checks them again.
+	Failed int
+}
+
+// String formats s as a one-line summary.
+func (s VerifyStats) String() string {
+	failed := ""
+	if s.Failed > 0 {
+		failed = fmt.Sprintf(", %d failed", s.Failed)
+	}
+	return fmt.Sprintf("%d previously verified, %d reachable, %d unverifiable, %d demoted%s",
+		s.PreviouslyVerified, s.Reachable, s.Unverifiable, s.Demoted, failed)
+}
+
+// maxVerifyRedirects bounds the redirects followed for one URL.
+const maxVerifyRedirects = 5
+
+// VerifyURLs checks that the LinkedInURL of each profile that has one and
+// no LinkedInStatus yet still resolves, recording the HTTP status in
+// LinkedInStatus. A URL answering 404 or 410 is demoted: it moves to the
+// end of PossibleLinkedInURLs and the next personal profile URL among
+// them, if any, becomes LinkedInURL and is checked in turn.
+//
+// Each URL gets a HEAD request, retried as a GET if LinkedIn refuses
+// HEAD. Redirects within linkedin.com are followed; a redirect to
+// LinkedIn's /404 page counts as a 404, and one to its login wall stops
+// the check with the redirect's status, since the profile can't be seen
+// without signing in. Checks run one at a time, at most one per
+// VerifyDelay, and share the Matcher's HTTP client, User-Agent, and
+// Limiter. The first context error stops the run; the profiles are
+// returned as far as they got.
+func (m *Matcher) VerifyURLs(ctx context.Context, profiles []scraper.Profile) ([]scraper.Profile, VerifyStats, error) {
+	var stats VerifyStats
+
+	out := make([]scraper.Profile, len(profiles))
+	copy(out, profiles)
+
+	delay := jitter.New(m.VerifyDelay, m.delayJitter)
+	for i, p := range out {
+		if p.LinkedInURL == "" {
+			continue
+		}
+		if p.LinkedInStatus != 0 {
+			stats.PreviouslyVerified++
+			continue
+		}
+
+		dead := make(map[string]bool)
+		for p.LinkedInURL != "" {
+			if err := delay.Wait(ctx); err != nil {
+				return out, stats, err
+			}
+
+			status, err := m.checkURL(ctx, p.LinkedInURL)
+			if err != nil {
+				if ctx.Err() != nil {
+					return out, stats, err
+				}
+				stats.Failed++
+				m.Logger.Warn("linkedin url check failed", "name", p.Name, "id", p.ID, "url", p.LinkedInURL, "err", err)
+				break
+			}
+			p.LinkedInStatus = status
+
+			switch {
+			case status == http.StatusNotFound || status == http.StatusGone:
+				stats.Demoted++
+				m.Logger.Info("demoting dead linkedin url", "name", p.Name, "id", p.ID, "url", p.LinkedInURL, "status", status)
+				dead[p.LinkedInURL] = true
+				p = demoteLinkedInURL(p, dead)
+				continue
+			case status >= 200 && status < 300:
+				stats.Reachable++
+			default:
+				stats.Unverifiable++
+				m.Logger.Debug("linkedin url could not be verified", "name", p.Name, "id", p.ID, "url", p.LinkedInURL, "status", status)
+			}
+			break
+		}
+		out[i] = p
+	}
+	return out, stats, nil
+}
+
+// demoteLinkedInURL moves p's LinkedInURL to the end of
+// PossibleLinkedInURLs and promotes the first personal profile URL among
+// them that isn't known to be dead. LinkedInStatus is cleared for the new
+// URL.
+func demoteLinkedInURL(p scraper.Profile, dead map[string]bool) scraper.Profile {
+	demoted := p.LinkedInURL
+	p.LinkedInURL = ""
+	p.LinkedInStatus = 0
+
+	possible := make([]string, 0, len(p.PossibleLinkedInURLs)+1)
+	for _, u := range p.PossibleLinkedInURLs {
+		if u == demoted {
+			continue
+		}
+		if p.LinkedInURL == "" && !dead[u] && isPersonalProfileURL(u) {
+			p.LinkedInURL = u
+			continue
+		}
+		possible = append(possible, u)
+	}
+	p.PossibleLinkedInURLs = dedupeCandidates(p.LinkedInURL, append(possible, demoted))
+	return p
+}
+
+// checkURL requests rawURL and returns the status it ends up with, as
+// described on VerifyURLs.
+func (m *Matcher) checkURL(ctx context.Context, rawURL string) (int, error) {
+	method := http.MethodHead
+	for hops := 0; ; hops++ {
+		resp, err := m.verifyRequest(ctx, method, rawURL)
+		if err != nil {
+			return 0, err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		if method == http.MethodHead && resp.StatusCode == http.StatusMethodNotAllowed {
+			method = http.MethodGet
+			continue
+		}
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+			return resp.StatusCode, nil
+		}
+
+		loc, err := resp.Location()
+		if errors.Is(err, http.ErrNoLocation) {
+			return resp.StatusCode, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		host := strings.ToLower(loc.Hostname())
+		if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+			return resp.StatusCode, nil
+		}
+		if isNotFoundPage(loc) {
+			return http.StatusNotFound, nil
+		}
+		if isLoginWall(loc) || hops >= maxVerifyRedirects {
+			return resp.StatusCode, nil
+		}
+		rawURL = loc.String()
+	}
+}
+
+// verifyRequest sends one check request without following redirects.
+func (m *Matcher) verifyRequest(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if m.userAgent != "" {
+		req.Header.Set("User-Agent", m.userAgent)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.Limiter != nil {
+		if err := m.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	client := *m.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if m.Metrics != nil {
+		m.Metrics.Inc(metrics.LinkedInURLChecks, "status", strconv.Itoa(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// isNotFoundPage reports whether u is LinkedIn's page for missing
+// profiles.
+func isNotFoundPage(u *url.URL) bool {
+	return u.Path == "/404" || strings.HasPrefix(u.Path, "/404/")
+}
+
+// isLoginWall reports whether u asks the visitor to sign in.
+func isLoginWall(u *url.URL) bool {
+	for _, prefix := range []string{"/authwall", "/login", "/uas/login", "/checkpoint", "/signup"} {
+		if strings.HasPrefix(u.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+// Package metrics counts what a run does and serves the counters in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Counter names used by the scraper and matcher.
+const (
+	// ProfilesScraped counts attendee profiles collected by a scrape.
+	ProfilesScraped = "profiles_scraped_total"
+	// HTTPRequests counts Brella API requests, labeled by endpoint and
+	// status (the HTTP status code, or "error" for transport failures).
+	HTTPRequests = "http_requests_total"
+	// SearchQueries counts search API requests, labeled by provider and
+	// status.
+	SearchQueries = "search_queries_total"
+	// Retries counts retried Brella requests, labeled by endpoint.
+	Retries = "retries_total"
+	// LinkedInURLChecks counts --verify-urls requests, labeled by status.
+	LinkedInURLChecks = "linkedin_url_checks_total"
+)
+
+var help = map[string]string{
+	ProfilesScraped:   "Attendee profiles collected.",
+	HTTPRequests:      "Brella API requests by endpoint and status.",
+	SearchQueries:     "Search API requests by provider and status.",
+	Retries:           "Retried Brella API requests by endpoint.",
+	LinkedInURLChecks: "LinkedIn URL verification requests by status.",
+}
+
+// Recorder receives counter increments. labels are alternating names and
+// values. Instrumented types treat a nil Recorder as disabled.
+type Recorder interface {
+	Inc(name string, labels ...string)
+}
+
+// Registry is an in-memory Recorder that serves its counters over HTTP.
+// The zero value is ready to use, it is safe for concurrent use, and a nil
+// *Registry ignores increments.
+type Registry struct {
+	mu       sync.Mutex
+	counters map[string]map[string]float64 // name -> rendered labels -> value
+}
+
+// Inc implements Recorder.
+func (r *Registry) Inc(name string, labels ...string) {
+	if r == nil {
+		return
+	}
+
+	key := renderLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counters == nil {
+		r.counters = make(map[string]map[string]float64)
+	}
+	series := r.counters[name]
+	if series == nil {
+		series = make(map[string]float64)
+		r.counters[name] = series
+	}
+	series[key]++
+}
+
+// ServeHTTP writes every counter in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.counters))
+	for name := range r.counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if h := help[name]; h != "" {
+			fmt.Fprintf(w, "# HELP %s %s\n", name, h)
+		}
+		fmt.Fprintf(w, "# TYPE %s counter\n", name)
+
+		series := r.counters[name]
+		keys := make([]string, 0, len(series))
+		for k := range series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s%s %s\n", name, k, strconv.FormatFloat(series[k], 'f', -1, 64))
+		}
+	}
+}
+
+// renderLabels formats name/value pairs as {a="1",b="2"}, or "" if there
+// are none. A trailing name without a value is dropped.
+func renderLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(labels[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"bitcoinconferencescraper/internal/bodylimit"
+	"bitcoinconferencescraper/internal/decompress"
+)
+
+// brellaRefreshResponse covers the token fields a refresh endpoint may
+// return in its body when it doesn't send them as response headers.
+type brellaRefreshResponse struct {
+	AccessToken     string `json:"access_token"`
+	AccessTokenDash string `json:"access-token"`
+	Client          string `json:"client"`
+	UID             string `json:"uid"`
+}
+
+// authHeaders returns the current Brella token headers and their
+// generation, which increases every time refreshAuth replaces them.
+func (c *Client) authHeaders() (accessToken, clientID, uid string, gen int) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.AccessToken, c.ClientID, c.UID, c.authGen
+}
+
+// refreshAuth exchanges RefreshToken for new access-token/client/uid
+// values by POSTing to RefreshPath. gen is the auth generation the failed
+// request was sent with; if another request has refreshed since, the new
+// tokens are reused instead of refreshing again.
+func (c *Client) refreshAuth(ctx context.Context, gen int) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.authGen != gen {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{"refresh_token": c.RefreshToken})
+	if err != nil {
+		return err
+	}
+
+	u, err := c.endpoint(c.RefreshPath)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", c.acceptMediaType())
+	decompress.Accept(req)
+	if c.AccessToken != "" {
+		req.Header.Set("access-token", c.AccessToken)
+	}
+	if c.ClientID != "" {
+		req.Header.Set("client", c.ClientID)
+	}
+	if c.UID != "" {
+		req.Header.Set("uid", c.UID)
+	}
+	if c.BrellaMediaType != "" {
+		req.Header.Set("x-brella-media-type", c.BrellaMediaType)
+	}
+	c.setClientHeaders(req)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := decompress.Body(resp); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("refresh status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	// devise_token_auth-style backends return the new tokens as headers.
+	accessToken := resp.Header.Get("access-token")
+	clientID := resp.Header.Get("client")
+	uid := resp.Header.Get("uid")
+
+	if accessToken == "" {
+		var rr brellaRefreshResponse
+		if err := json.NewDecoder(bodylimit.Wrap(resp.Body, c.MaxResponseBytes)).Decode(&rr); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: decoding refresh response: %w", ErrDecode, err)
+		}
+		accessToken = rr.AccessToken
+		if accessToken == "" {
+			accessToken = rr.AccessTokenDash
+		}
+		if clientID == "" {
+			clientID = rr.Client
+		}
+		if uid == "" {
+			uid = rr.UID
+		}
+	}
+
+	if accessToken == "" {
+		return errors.New("refresh response contained no access token")
+	}
+
+	c.AccessToken = accessToken
+	if clientID != "" {
+		c.ClientID = clientID
+	}
+	if uid != "" {
+		c.UID = uid
+	}
+	c.authGen++
+	if c.OnTokenRefresh != nil {
+		c.OnTokenRefresh(c.AccessToken, c.ClientID, c.UID)
+	}
+
+	c.logger().Info("refreshed Brella access token")
+	return nil
+}
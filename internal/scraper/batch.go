@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrBatchUnsupported is returned by GetAttendeeProfiles when the API
+// answered the batch request with something other than the requested
+// attendees: attendees that weren't asked for (the ID filter was ignored),
+// or records without their user (the includes were ignored).
+var ErrBatchUnsupported = errors.New("attendee batch fetch not supported by the API")
+
+// BatchProfileGetter is implemented by listers that can fetch several
+// attendee details in one request. *Client implements it.
+type BatchProfileGetter interface {
+	GetAttendeeProfiles(ctx context.Context, eventID string, attendeeIDs []string) ([]Profile, error)
+}
+
+// GetAttendeeProfiles fetches the details of several attendees in one
+// request, by filtering the attendee list by ID and including the users
+// and interests:
+//
+//	GET /api/events/{eventID}/attendees
+//	    ?filter[id]={id1},{id2},...
+//	    &include=user,interests
+//	    &page[size]={len(attendeeIDs)}
+//
+// Profiles come back in the API's order. Attendees the API left out are
+// missing from the result, and a profile can be Incomplete; callers fetch
+// those one at a time with GetAttendeeProfile. Brella doesn't document
+// this filter, so a response that doesn't honor it fails with an error
+// wrapping ErrBatchUnsupported.
+func (c *Client) GetAttendeeProfiles(ctx context.Context, eventID string, attendeeIDs []string) ([]Profile, error) {
+	if eventID == "" {
+		return nil, errors.New("eventID is empty")
+	}
+	if len(attendeeIDs) == 0 {
+		return nil, nil
+	}
+
+	path := fmt.Sprintf(
+		"/api/events/%s/attendees?filter[id]=%s&include=user,interests&page[size]=%d",
+		eventID,
+		url.QueryEscape(strings.Join(attendeeIDs, ",")),
+		len(attendeeIDs),
+	)
+
+	resp, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp brellaAttendeeBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("%w: decoding attendee batch: %w", ErrDecode, err)
+	}
+
+	requested := make(map[string]bool, len(attendeeIDs))
+	for _, id := range attendeeIDs {
+		requested[id] = true
+	}
+
+	profiles := make([]Profile, 0, len(apiResp.Data))
+	withUser := 0
+	for _, a := range apiResp.Data {
+		if !requested[a.ID] {
+			return nil, fmt.Errorf("%w: got attendee %s, which wasn't requested", ErrBatchUnsupported, a.ID)
+		}
+		if a.Relationships.User.Data.ID != "" {
+			withUser++
+		}
+		p := mapBrellaAttendee(a, apiResp.Included, c.FieldMap)
+		p.Location = c.Location.location(p)
+		profiles = append(profiles, p)
+	}
+	if len(profiles) > 0 && withUser == 0 {
+		return nil, fmt.Errorf("%w: no attendee came with its user", ErrBatchUnsupported)
+	}
+	return profiles, nil
+}
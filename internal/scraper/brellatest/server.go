@@ -0,0 +1,401 @@
+// Package brellatest provides a fake Brella API server for exercising the
+// scraper client without hitting the real backend.
+package brellatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attendee is one canned attendee served by Server. UserID defaults to
+// "u-" + ID when empty.
+type Attendee struct {
+	ID        string
+	UserID    string
+	FirstName string
+	LastName  string
+	Title     string
+	Company   string
+	LinkedIn  string
+	Twitter   string
+	Website   string
+	Email     string
+	TimeZone  string
+	Countries []string
+	Tags      []string
+
+	// Interests are served as included interest records referenced from
+	// the attendee.
+	Interests []string
+
+	// Industry and CompanySize, if either is set, are served on an
+	// included company record named Company and referenced from the
+	// user.
+	Industry    string
+	CompanySize string
+
+	// CreatedAt, if set, is served as the attendee's created-at attribute.
+	CreatedAt time.Time
+
+	// OmitUser leaves the user out of the detail response's included
+	// array, mimicking a partially populated record.
+	OmitUser bool
+}
+
+// Sponsor is one canned sponsor served by Server.
+type Sponsor struct {
+	ID       string
+	Name     string
+	Website  string
+	LinkedIn string
+	Twitter  string
+}
+
+// Event is the event record served by Server. Zero fields are left out of
+// the response.
+type Event struct {
+	Name     string
+	StartsAt time.Time
+	EndsAt   time.Time
+	TimeZone string
+	Location string
+}
+
+// Server is an httptest.Server that answers the Brella event, attendee
+// list, attendee detail, speaker list, and sponsor list endpoints for a
+// single event with JSON:API responses.
+type Server struct {
+	*httptest.Server
+
+	// Event is served at /api/events/{eventID}. Set it before the first
+	// request.
+	Event Event
+
+	// Batch makes the attendee list honor filter[id], answering with the
+	// full records of those attendees as GetAttendeeProfiles expects.
+	// Without it the filter is ignored, like an API that doesn't support
+	// batches. Set it before the first request.
+	Batch bool
+
+	// Speakers and Sponsors are served by the speakers and sponsors list
+	// endpoints. A speaker's name, title, company, and links are attributes
+	// of the speaker record itself. Set them before the first request.
+	Speakers []Attendee
+	Sponsors []Sponsor
+
+	eventID   string
+	attendees []Attendee
+
+	mu       sync.Mutex
+	failures map[string][]int
+	requests []string
+}
+
+// NewServer starts a fake Brella API serving attendees for eventID.
+// Callers must Close it when done.
+func NewServer(eventID string, attendees []Attendee) *Server {
+	s := &Server{
+		eventID:   eventID,
+		attendees: attendees,
+		failures:  make(map[string][]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Fail makes the next len(statuses) requests whose path equals path answer
+// with those statuses, in order, before it is served normally again. For
+// example Fail("/api/events/E/attendees/a1", 502, 429) yields a 502, then
+// a 429, then the real response.
+func (s *Server) Fail(path string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = append(s.failures[path], statuses...)
+}
+
+// Requests returns the request URIs (path and query) received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	var status int
+	if queued := s.failures[r.URL.Path]; len(queued) > 0 {
+		status = queued[0]
+		s.failures[r.URL.Path] = queued[1:]
+	}
+	s.mu.Unlock()
+
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	prefix := "/api/events/" + s.eventID + "/attendees"
+	switch {
+	case r.URL.Path == "/api/events/"+s.eventID:
+		s.event(w)
+	case r.URL.Path == prefix:
+		s.list(w, r)
+	case r.URL.Path == "/api/events/"+s.eventID+"/speakers":
+		s.speakers(w, r)
+	case r.URL.Path == "/api/events/"+s.eventID+"/sponsors":
+		s.sponsors(w, r)
+	case strings.HasPrefix(r.URL.Path, prefix+"/"):
+		s.detail(w, strings.TrimPrefix(r.URL.Path, prefix+"/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) event(w http.ResponseWriter) {
+	attributes := map[string]any{}
+	for name, v := range map[string]string{"name": s.Event.Name, "time-zone": s.Event.TimeZone, "location": s.Event.Location} {
+		if v != "" {
+			attributes[name] = v
+		}
+	}
+	if !s.Event.StartsAt.IsZero() {
+		attributes["start-time"] = s.Event.StartsAt.Format(time.RFC3339)
+	}
+	if !s.Event.EndsAt.IsZero() {
+		attributes["end-time"] = s.Event.EndsAt.Format(time.RFC3339)
+	}
+
+	writeJSON(w, map[string]any{
+		"data": map[string]any{
+			"id":         s.eventID,
+			"type":       "event",
+			"attributes": attributes,
+		},
+	})
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if ids := q.Get("filter[id]"); ids != "" && s.Batch {
+		s.batch(w, ids)
+		return
+	}
+
+	page, size := pagination(r)
+
+	listed := s.attendees
+	if search := strings.ToLower(strings.TrimSpace(q.Get("search"))); search != "" {
+		listed = nil
+		for _, a := range s.attendees {
+			if a.matches(search) {
+				listed = append(listed, a)
+			}
+		}
+	}
+
+	data := []map[string]any{}
+	for i := (page - 1) * size; i < page*size && i < len(listed); i++ {
+		data = append(data, map[string]any{"id": listed[i].ID, "type": "attendee"})
+	}
+
+	writeJSON(w, map[string]any{"data": data, "meta": meta(len(listed), size)})
+}
+
+func (s *Server) speakers(w http.ResponseWriter, r *http.Request) {
+	page, size := pagination(r)
+
+	data := []map[string]any{}
+	for i := (page - 1) * size; i < page*size && i < len(s.Speakers); i++ {
+		sp := s.Speakers[i]
+		data = append(data, map[string]any{
+			"id":   sp.ID,
+			"type": "speaker",
+			"attributes": map[string]any{
+				"first-name": sp.FirstName,
+				"last-name":  sp.LastName,
+				"title":      sp.Title,
+				"company":    sp.Company,
+				"linkedin":   sp.LinkedIn,
+				"twitter":    sp.Twitter,
+				"website":    sp.Website,
+			},
+		})
+	}
+	writeJSON(w, map[string]any{"data": data, "meta": meta(len(s.Speakers), size)})
+}
+
+func (s *Server) sponsors(w http.ResponseWriter, r *http.Request) {
+	page, size := pagination(r)
+
+	data := []map[string]any{}
+	for i := (page - 1) * size; i < page*size && i < len(s.Sponsors); i++ {
+		sp := s.Sponsors[i]
+		data = append(data, map[string]any{
+			"id":   sp.ID,
+			"type": "sponsor",
+			"attributes": map[string]any{
+				"name":     sp.Name,
+				"website":  sp.Website,
+				"linkedin": sp.LinkedIn,
+				"twitter":  sp.Twitter,
+			},
+		})
+	}
+	writeJSON(w, map[string]any{"data": data, "meta": meta(len(s.Sponsors), size)})
+}
+
+// pagination returns the page number and size a list request asks for,
+// defaulting to the first page of 50.
+func pagination(r *http.Request) (page, size int) {
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page[number]"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err = strconv.Atoi(q.Get("page[size]"))
+	if err != nil || size < 1 {
+		size = 50
+	}
+	return page, size
+}
+
+// meta returns the pagination metadata of a list of total records served
+// size at a time.
+func meta(total, size int) map[string]any {
+	return map[string]any{
+		"total-count": total,
+		"total-pages": (total + size - 1) / size,
+	}
+}
+
+// matches reports whether the lowercase search term occurs in a's name,
+// title, or company, which is roughly what Brella's attendee search looks
+// at.
+func (a Attendee) matches(search string) bool {
+	for _, v := range []string{a.FirstName + " " + a.LastName, a.Title, a.Company} {
+		if strings.Contains(strings.ToLower(v), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) detail(w http.ResponseWriter, id string) {
+	for _, a := range s.attendees {
+		if a.ID == id {
+			data, included := a.record()
+			writeJSON(w, map[string]any{"data": data, "included": included})
+			return
+		}
+	}
+
+	http.Error(w, fmt.Sprintf("attendee %s not found", id), http.StatusNotFound)
+}
+
+// batch answers a list request filtered by ID with the full records of
+// the listed attendees, as the detail endpoint serves them one at a time.
+func (s *Server) batch(w http.ResponseWriter, ids string) {
+	want := make(map[string]bool)
+	for _, id := range strings.Split(ids, ",") {
+		want[id] = true
+	}
+
+	data := []map[string]any{}
+	included := []map[string]any{}
+	for _, a := range s.attendees {
+		if want[a.ID] {
+			record, inc := a.record()
+			data = append(data, record)
+			included = append(included, inc...)
+		}
+	}
+	writeJSON(w, map[string]any{"data": data, "included": included})
+}
+
+// record returns a's JSON:API attendee record and the user and interest
+// records it references.
+func (a Attendee) record() (map[string]any, []map[string]any) {
+	userID := a.UserID
+	if userID == "" {
+		userID = "u-" + a.ID
+	}
+
+	userRels := map[string]any{}
+	included := []map[string]any{}
+	if a.Industry != "" || a.CompanySize != "" {
+		companyID := "c-" + a.ID
+		userRels["company"] = map[string]any{
+			"data": map[string]any{"id": companyID, "type": "company"},
+		}
+		included = append(included, map[string]any{
+			"id":   companyID,
+			"type": "company",
+			"attributes": map[string]any{
+				"name":     a.Company,
+				"industry": a.Industry,
+				"size":     a.CompanySize,
+			},
+		})
+	}
+	if !a.OmitUser {
+		included = append(included, map[string]any{
+			"id":            userID,
+			"type":          "user",
+			"relationships": userRels,
+			"attributes": map[string]any{
+				"first-name":        a.FirstName,
+				"last-name":         a.LastName,
+				"company-title":     a.Title,
+				"company-name":      a.Company,
+				"linkedin":          a.LinkedIn,
+				"twitter":           a.Twitter,
+				"website":           a.Website,
+				"email":             a.Email,
+				"time-zone":         a.TimeZone,
+				"company-countries": a.Countries,
+				"tags":              a.Tags,
+			},
+		})
+	}
+
+	interestRefs := []map[string]any{}
+	for i, name := range a.Interests {
+		id := fmt.Sprintf("i-%s-%d", a.ID, i)
+		interestRefs = append(interestRefs, map[string]any{"id": id, "type": "interest"})
+		included = append(included, map[string]any{
+			"id":         id,
+			"type":       "interest",
+			"attributes": map[string]any{"name": name},
+		})
+	}
+
+	attributes := map[string]any{}
+	if !a.CreatedAt.IsZero() {
+		attributes["created-at"] = a.CreatedAt.Format(time.RFC3339)
+	}
+
+	data := map[string]any{
+		"id":         a.ID,
+		"type":       "attendee",
+		"attributes": attributes,
+		"relationships": map[string]any{
+			"user": map[string]any{
+				"data": map[string]any{"id": userID, "type": "user"},
+			},
+			"interests": map[string]any{"data": interestRefs},
+		},
+	}
+	return data, included
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/vnd.brella.v4+json")
+	json.NewEncoder(w).Encode(v)
+}
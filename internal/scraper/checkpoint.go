@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bitcoinconferencescraper/internal/atomicfile"
+)
+
+// Checkpoint is the on-disk scrape state written to Scraper.CheckpointPath.
+// It records every profile collected so far (or, for a Stream scrape, their
+// IDs) plus the last page whose attendees were all fetched, so an
+// interrupted scrape can continue from the next page instead of starting
+// over.
+type Checkpoint struct {
+	EventID           string    `json:"event_id"`
+	Search            string    `json:"search,omitempty"`
+	LastCompletedPage int       `json:"last_completed_page"`
+	Profiles          []Profile `json:"profiles"`
+
+	// PageSize is the page size LastCompletedPage counts in, which
+	// AutoPageSize may have lowered mid-scrape. Zero in older checkpoints,
+	// which are assumed to match the current page size.
+	PageSize int `json:"page_size,omitempty"`
+
+	// Unfetched lists the attendees a ListOnly scrape listed without
+	// fetching their details, in list order. A scrape that isn't ListOnly
+	// fetches them before listing further pages.
+	Unfetched []string `json:"unfetched,omitempty"`
+
+	// Fetched lists the attendees a Stream scrape fetched and handed to
+	// OnProfile without keeping them, so resuming skips them. Their
+	// profiles are wherever OnProfile put them.
+	Fetched []string `json:"fetched,omitempty"`
+}
+
+// LoadCheckpoint reads a checkpoint file. A missing file is not an error;
+// it yields an empty checkpoint so callers can treat "no checkpoint yet"
+// the same as a fresh start.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	defer f.Close()
+
+	var cp Checkpoint
+	if err := json.NewDecoder(f).Decode(&cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("decoding checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// rescale converts LastCompletedPage to pages of size when the checkpoint
+// was written with another page size. A page only partly covered counts as
+// not completed, so its attendees are listed again and skipped as already
+// fetched.
+func (cp *Checkpoint) rescale(size int) {
+	if cp.PageSize > 0 && cp.PageSize != size {
+		cp.LastCompletedPage = cp.LastCompletedPage * cp.PageSize / size
+	}
+	cp.PageSize = size
+}
+
+// eventCheckpointPath returns the checkpoint file used for one event of a
+// multi-event scrape: path with the event ID inserted before the extension,
+// so "progress.json" becomes "progress.AMS25.json".
+func eventCheckpointPath(path, eventID string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + eventID + ext
+}
+
+// saveCheckpoint writes the checkpoint to path, replacing any previous one
+// only once the new one is fully written.
+func saveCheckpoint(path string, cp Checkpoint) error {
+	f, err := atomicfile.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cp); err != nil {
+		return err
+	}
+	return f.Commit()
+}
@@ -5,11 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"bitcoinconferencescraper/internal/activehours"
+	"bitcoinconferencescraper/internal/breaker"
+	"bitcoinconferencescraper/internal/decompress"
+	"bitcoinconferencescraper/internal/metrics"
+	"bitcoinconferencescraper/internal/throttle"
 )
 
+// DefaultAcceptMediaType is the vendor media type (API version) Brella
+// requests ask for unless Client.AcceptMediaType overrides it.
+const DefaultAcceptMediaType = "application/vnd.brella.v4+json"
+
+// DefaultUserAgent is the User-Agent Brella requests carry unless
+// Client.UserAgent overrides it.
+const DefaultUserAgent = "bitcoinconferencescraper/1.0"
+
 // Client wraps HTTP access to the Bitcoin Conference API.
 type Client struct {
 	BaseURL    string
@@ -18,19 +39,127 @@ type Client struct {
 	// AuthToken is used for Authorization: Bearer <token>, if set.
 	AuthToken string
 
-	// Optional Brella-specific auth headers.
+	// Optional Brella-specific auth headers. AccessToken, ClientID, and UID
+	// may be replaced by a token refresh while requests are in flight, so
+	// set them before starting a scrape.
 	AccessToken     string
 	ClientID        string
 	UID             string
 	SessionCookie   string
 	BrellaMediaType string
+
+	// Cookies, if set, is sent verbatim as the Cookie header in place of
+	// the _brella_session cookie built from SessionCookie.
+	Cookies string
+
+	// AcceptMediaType is sent as the Accept header, selecting the Brella API
+	// version. Empty means DefaultAcceptMediaType.
+	AcceptMediaType string
+
+	// UserAgent is sent as the User-Agent header. Empty means
+	// DefaultUserAgent.
+	UserAgent string
+
+	// ExtraHeaders are set on every request after all other headers, so
+	// they can add headers the backend turns out to expect or override
+	// any of the ones above.
+	ExtraHeaders map[string]string
+
+	// RefreshPath and RefreshToken enable automatic token refresh: when a
+	// request returns 401, RefreshToken is POSTed to RefreshPath, the new
+	// access-token/client/uid values replace the current ones, and the
+	// request is retried once. Refresh is off if RefreshPath is empty.
+	RefreshPath  string
+	RefreshToken string
+
+	// OnTokenRefresh, if set, is called with the new values after each
+	// token refresh, for example to mask them in log output like the
+	// configured ones.
+	OnTokenRefresh func(accessToken, clientID, uid string)
+
+	authMu  sync.Mutex
+	authGen int
+
+	// MaxRetries is how many times a request is retried after a 429, a 5xx,
+	// or a transient network error such as a timeout or reset connection.
+	// Zero disables retries.
+	MaxRetries int
+
+	// RetryBudget, if > 0, caps the retries of all requests made through
+	// the client combined. Once it is spent, a request that would be
+	// retried fails with an error wrapping ErrRetryBudgetExhausted instead.
+	RetryBudget int
+
+	// retriesUsed counts retries against RetryBudget.
+	retriesUsed atomic.Int64
+
+	// BaseRetryDelay is the backoff before the first retry; it doubles with
+	// each subsequent attempt. A 429 Retry-After header takes precedence
+	// when it asks for a longer wait.
+	BaseRetryDelay time.Duration
+
+	// FieldMap maps Profile fields, by their JSON names, to the user
+	// attributes to fill them from, for when Brella renames an attribute
+	// ({"title": "position"}). A mapped attribute that is present and not
+	// empty overrides the built-in one. Keys must be in FieldMapFields;
+	// see CheckFieldMap.
+	FieldMap map[string]string
+
+	// Location selects how Profile.Location is filled from an attendee's
+	// company countries and time zone. Empty means
+	// LocationCountriesFirst.
+	Location LocationStrategy
+
+	// MaxResponseBytes, if > 0, caps the size of a response body. Reading
+	// past it fails with an error wrapping bodylimit.ErrTooLarge, which is
+	// not retried.
+	MaxResponseBytes int64
+
+	// RequestTimeout bounds each individual attempt, including reading the
+	// response body, independently of the HTTP client's overall Timeout.
+	// An attempt that times out is retried like a network error. Zero
+	// means no per-attempt limit.
+	RequestTimeout time.Duration
+
+	// Limiter, if set, is waited on before every outbound HTTP request,
+	// retries included. Sharing one limiter with the LinkedIn matcher caps
+	// the combined request rate of a run.
+	Limiter *rate.Limiter
+
+	// ActiveHours, if set, holds back requests outside its daily window:
+	// one due outside it waits until the window opens again, so a scrape
+	// left running pauses through the backend's busy hours.
+	ActiveHours *activehours.Window
+
+	// pausedUntil is when the active-hours pause last logged ends, in Unix
+	// seconds, so concurrent requests log each pause once.
+	pausedUntil atomic.Int64
+
+	// Throttle, if set, paces requests from the rate-limit headers on
+	// Brella's responses: it slows down as the remaining quota drops and
+	// pauses until the window resets once it is used up. NewClient sets
+	// one; it applies on top of Limiter.
+	Throttle *throttle.Throttle
+
+	// Breaker, if set, stops requests after repeated 429s, 5xx responses,
+	// or transient network errors: while it is open, requests fail fast with an
+	// error wrapping breaker.ErrOpen instead of being retried.
+	Breaker *breaker.Breaker
+
+	// Metrics, if set, counts requests by endpoint and status, and
+	// retries.
+	Metrics metrics.Recorder
+
+	// Logger receives retry warnings. Defaults to slog.Default().
+	Logger *slog.Logger
 }
 
 // NewClient constructs a new API client.
 //
 // BaseURL should be the scheme + host (and optional base path) you discover
 // in Proxyman when the app calls its backend, for example:
-//   https://api.bitcoinconference.com/v1
+//
+//	https://api.bitcoinconference.com/v1
 func NewClient(baseURL, authToken string, httpClient *http.Client) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
@@ -40,6 +169,7 @@ func NewClient(baseURL, authToken string, httpClient *http.Client) *Client {
 		BaseURL:    baseURL,
 		HTTPClient: httpClient,
 		AuthToken:  authToken,
+		Throttle:   &throttle.Throttle{},
 	}
 }
 
@@ -49,91 +179,162 @@ func NewClient(baseURL, authToken string, httpClient *http.Client) *Client {
 type ListProfilesResult struct {
 	Profiles []Profile
 	HasNext  bool
+
+	// Total is the total number of attendees reported by the API's
+	// pagination metadata, or 0 if the response didn't include it.
+	Total int
 }
 
 // brellaAttendeesListResponse models the minimal fields we need from the
-// attendees list endpoint: just the attendee IDs.
+// attendees list endpoint: the attendee IDs and the JSON:API pagination
+// metadata, when present.
 type brellaAttendeesListResponse struct {
 	Data []struct {
 		ID string `json:"id"`
 	} `json:"data"`
+	Meta *brellaListMeta `json:"meta"`
+}
+
+// brellaListMeta is the JSON:API pagination metadata of a list response.
+type brellaListMeta struct {
+	TotalCount *int `json:"total-count"`
+	TotalPages *int `json:"total-pages"`
 }
 
 // brellaAttendeeDetailResponse models the structure of the per-attendee
 // detail endpoint, focusing on the attendee's user information.
 type brellaAttendeeDetailResponse struct {
+	Data     brellaAttendee   `json:"data"`
+	Included []brellaIncluded `json:"included"`
+}
+
+// brellaAttendeeBatchResponse is the attendee list endpoint filtered by
+// ID, with the same records as the detail endpoint, several at once.
+type brellaAttendeeBatchResponse struct {
+	Data     []brellaAttendee `json:"data"`
+	Included []brellaIncluded `json:"included"`
+}
+
+// brellaAttendee is an attendee record with its relationships.
+type brellaAttendee struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Attributes struct {
+		CreatedAt string `json:"created-at"`
+	} `json:"attributes"`
+	Relationships struct {
+		User struct {
+			Data struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"data"`
+		} `json:"user"`
+		Interests struct {
+			Data []struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"data"`
+		} `json:"interests"`
+		Company brellaCompanyRef `json:"company"`
+	} `json:"relationships"`
+}
+
+// brellaCompanyRef is a relationship to an included company record. Data
+// is null when the attendee or user has no company.
+type brellaCompanyRef struct {
 	Data struct {
-		ID             string `json:"id"`
-		Type           string `json:"type"`
-		Relationships  struct {
-			User struct {
-				Data struct {
-					ID   string `json:"id"`
-					Type string `json:"type"`
-				} `json:"data"`
-			} `json:"user"`
-		} `json:"relationships"`
+		ID   string `json:"id"`
+		Type string `json:"type"`
 	} `json:"data"`
-	Included []struct {
-		ID         string `json:"id"`
-		Type       string `json:"type"`
-		Attributes struct {
-			FirstName        string   `json:"first-name"`
-			LastName         string   `json:"last-name"`
-			CompanyTitle     string   `json:"company-title"`
-			CompanyName      string   `json:"company-name"`
-			LinkedIn         string   `json:"linkedin"`
-			Twitter          string   `json:"twitter"`
-			Website          string   `json:"website"`
-			TimeZone         string   `json:"time-zone"`
-			CompanyCountries []string `json:"company-countries"`
-		} `json:"attributes"`
-	} `json:"included"`
+}
+
+// brellaIncluded is an included user, interest, or company record.
+type brellaIncluded struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Attributes struct {
+		FirstName        string   `json:"first-name"`
+		LastName         string   `json:"last-name"`
+		CompanyTitle     string   `json:"company-title"`
+		CompanyName      string   `json:"company-name"`
+		LinkedIn         string   `json:"linkedin"`
+		Twitter          string   `json:"twitter"`
+		Website          string   `json:"website"`
+		Email            string   `json:"email"`
+		TimeZone         string   `json:"time-zone"`
+		CompanyCountries []string `json:"company-countries"`
+		Tags             []string `json:"tags"`
+
+		// Name is set on included interest and company records.
+		Name string `json:"name"`
+
+		// Industry and Size are set on included company records.
+		Industry string     `json:"industry"`
+		Size     brellaText `json:"size"`
+	} `json:"attributes"`
+	Relationships struct {
+		Company brellaCompanyRef `json:"company"`
+	} `json:"relationships"`
+
+	// rawAttributes are the attributes by key, for Client.FieldMap.
+	rawAttributes map[string]json.RawMessage
+}
+
+// brellaText is an attribute Brella may send as a string or a number, such
+// as a company size of "11-50" or 30.
+type brellaText string
+
+func (t *brellaText) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = brellaText(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*t = brellaText(n)
+	return nil
 }
 
 // ListProfiles calls the Brella attendees endpoint for a specific event and page.
 //
 // Example endpoint (URL-encoded brackets removed for clarity):
-//   GET /api/events/{eventID}/attendees
-//       ?ignore_networking=true
-//       &order=newest
-//       &page[number]={page}
-//       &page[size]={pageSize}
-//       &search=
 //
-// The HasNext flag is inferred heuristically: if the API returns fewer than
-// pageSize attendees, we assume there are no more pages.
-func (c *Client) ListProfiles(ctx context.Context, eventID string, page, pageSize int) (ListProfilesResult, error) {
+//	GET /api/events/{eventID}/attendees
+//	    ?ignore_networking=true
+//	    &order=newest
+//	    &page[number]={page}
+//	    &page[size]={pageSize}
+//	    &search={search}
+//
+// search, if not empty, asks Brella to list only attendees matching it.
+// HasNext is computed from the response's meta block (total-pages, or
+// total-count divided by pageSize). If the API omits that metadata, it is
+// inferred heuristically: fewer than pageSize attendees means no more pages.
+func (c *Client) ListProfiles(ctx context.Context, eventID, search string, page, pageSize int) (ListProfilesResult, error) {
 	if eventID == "" {
 		return ListProfilesResult{}, errors.New("eventID is empty")
 	}
 
 	path := fmt.Sprintf(
-		"/api/events/%s/attendees?ignore_networking=true&order=newest&page[number]=%d&page[size]=%d&search=",
+		"/api/events/%s/attendees?ignore_networking=true&order=newest&page[number]=%d&page[size]=%d&search=%s",
 		eventID,
 		page,
 		pageSize,
+		url.QueryEscape(search),
 	)
 
-	req, err := c.newRequest(ctx, http.MethodGet, path)
-	if err != nil {
-		return ListProfilesResult{}, err
-	}
-
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.get(ctx, path)
 	if err != nil {
 		return ListProfilesResult{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return ListProfilesResult{}, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
 	var apiResp brellaAttendeesListResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return ListProfilesResult{}, fmt.Errorf("decoding attendees response: %w", err)
+		return ListProfilesResult{}, fmt.Errorf("%w: decoding attendees response: %w", ErrDecode, err)
 	}
 
 	profiles := make([]Profile, 0, len(apiResp.Data))
@@ -146,12 +347,30 @@ func (c *Client) ListProfiles(ctx context.Context, eventID string, page, pageSiz
 		})
 	}
 
-	hasNext := len(apiResp.Data) == pageSize
+	return listResult(profiles, len(apiResp.Data), page, pageSize, apiResp.Meta), nil
+}
 
-	return ListProfilesResult{
+// listResult builds the ListProfilesResult of a list page that had listed
+// records, working out HasNext and Total from meta as ListProfiles
+// describes.
+func listResult(profiles []Profile, listed, page, pageSize int, meta *brellaListMeta) ListProfilesResult {
+	result := ListProfilesResult{
 		Profiles: profiles,
-		HasNext:  hasNext,
-	}, nil
+		HasNext:  listed == pageSize,
+	}
+
+	if meta != nil {
+		if meta.TotalCount != nil {
+			result.Total = *meta.TotalCount
+		}
+		switch {
+		case meta.TotalPages != nil:
+			result.HasNext = page < *meta.TotalPages
+		case meta.TotalCount != nil && pageSize > 0:
+			result.HasNext = page*pageSize < *meta.TotalCount
+		}
+	}
+	return result
 }
 
 // GetAttendeeProfile fetches detailed profile data for a single attendee.
@@ -165,38 +384,53 @@ func (c *Client) GetAttendeeProfile(ctx context.Context, eventID, attendeeID str
 
 	path := fmt.Sprintf("/api/events/%s/attendees/%s", eventID, attendeeID)
 
-	req, err := c.newRequest(ctx, http.MethodGet, path)
-	if err != nil {
-		return Profile{}, err
+	profile, err := c.getAttendee(ctx, path)
+	if err != nil || !profile.Incomplete {
+		return profile, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	// A missing user record is usually a transient backend hiccup; ask
+	// once more before settling for the ID alone.
+	c.logger().Warn("attendee detail missing its user record, retrying once", "attendee_id", attendeeID)
+	profile, err = c.getAttendee(ctx, path)
+	if err == nil && profile.Incomplete {
+		c.logger().Warn("attendee detail still missing its user record; keeping incomplete profile", "attendee_id", attendeeID)
+	}
+	return profile, err
+}
+
+// getAttendee fetches and maps one attendee detail response.
+func (c *Client) getAttendee(ctx context.Context, path string) (Profile, error) {
+	resp, err := c.get(ctx, path)
 	if err != nil {
 		return Profile{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return Profile{}, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
 	var apiResp brellaAttendeeDetailResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return Profile{}, fmt.Errorf("decoding attendee detail: %w", err)
+		return Profile{}, fmt.Errorf("%w: decoding attendee detail: %w", ErrDecode, err)
 	}
 
-	return mapBrellaDetailToProfile(apiResp), nil
+	profile := mapBrellaDetailToProfile(apiResp, c.FieldMap)
+	profile.Location = c.Location.location(profile)
+	return profile, nil
+}
+
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.Default()
 }
 
 // newRequest is a helper to build an HTTP request with auth headers, etc.
 func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
-	if c.BaseURL == "" {
-		return nil, errors.New("client BaseURL is empty")
+	u, err := c.endpoint(path)
+	if err != nil {
+		return nil, err
 	}
-
-	url := fmt.Sprintf("%s%s", c.BaseURL, path)
-	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -204,62 +438,231 @@ func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Req
 	if c.AuthToken != "" {
 		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
 	}
-	if c.AccessToken != "" {
-		req.Header.Set("access-token", c.AccessToken)
+	accessToken, clientID, uid, _ := c.authHeaders()
+	if accessToken != "" {
+		req.Header.Set("access-token", accessToken)
 	}
-	if c.ClientID != "" {
-		req.Header.Set("client", c.ClientID)
+	if clientID != "" {
+		req.Header.Set("client", clientID)
 	}
-	if c.UID != "" {
-		req.Header.Set("uid", c.UID)
+	if uid != "" {
+		req.Header.Set("uid", uid)
 	}
 	if c.BrellaMediaType != "" {
 		req.Header.Set("x-brella-media-type", c.BrellaMediaType)
 	}
-	if c.SessionCookie != "" {
+	if c.Cookies != "" {
+		req.Header.Set("Cookie", c.Cookies)
+	} else if c.SessionCookie != "" {
 		// Expect just the cookie value here, not the full Set-Cookie string.
 		req.Header.Add("Cookie", "_brella_session="+c.SessionCookie)
 	}
 
 	// Use the vendor-specific media type expected by Brella.
-	req.Header.Set("Accept", "application/vnd.brella.v4+json")
+	req.Header.Set("Accept", c.acceptMediaType())
+	decompress.Accept(req)
+	c.setClientHeaders(req)
 	return req, nil
 }
+
+// endpoint resolves path, an absolute path with an optional query, under
+// BaseURL. Any base path BaseURL has is kept whether or not it ends in a
+// slash: with https://host/v1 or https://host/v1/, "/api/events/E"
+// becomes https://host/v1/api/events/E.
+func (c *Client) endpoint(path string) (string, error) {
+	if c.BaseURL == "" {
+		return "", errors.New("client BaseURL is empty")
+	}
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("client BaseURL: %w", err)
+	}
+	// A directory, so the path resolves beneath it rather than beside it.
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+		if base.RawPath != "" {
+			base.RawPath += "/"
+		}
+	}
+
+	// "./" keeps a colon in the first segment from reading as a scheme.
+	ref, err := url.Parse("./" + strings.TrimLeft(path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("request path %q: %w", path, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// setClientHeaders sets the User-Agent and ExtraHeaders on req.
+func (c *Client) setClientHeaders(req *http.Request) {
+	ua := c.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	for name, value := range c.ExtraHeaders {
+		req.Header.Set(name, value)
+	}
+}
+
+func (c *Client) acceptMediaType() string {
+	if c.AcceptMediaType != "" {
+		return c.AcceptMediaType
+	}
+	return DefaultAcceptMediaType
+}
+
 // mapBrellaDetailToProfile converts a detailed attendee response into a Profile.
-func mapBrellaDetailToProfile(resp brellaAttendeeDetailResponse) Profile {
+func mapBrellaDetailToProfile(resp brellaAttendeeDetailResponse, fieldMap map[string]string) Profile {
+	return mapBrellaAttendee(resp.Data, resp.Included, fieldMap)
+}
+
+// mapBrellaAttendee converts attendee a into a Profile, reading its user,
+// interests, and company from included, and the user attributes fieldMap
+// maps. Location is left for the Client's LocationStrategy to fill in.
+func mapBrellaAttendee(a brellaAttendee, included []brellaIncluded, fieldMap map[string]string) Profile {
 	profile := Profile{
-		ID: resp.Data.ID,
+		ID:         a.ID,
+		Role:       RoleAttendee,
+		RecordType: a.Type,
+	}
+	if t, err := time.Parse(time.RFC3339, a.Attributes.CreatedAt); err == nil {
+		profile.RegisteredAt = t
 	}
 
-	userID := resp.Data.Relationships.User.Data.ID
+	userID := a.Relationships.User.Data.ID
 	if userID == "" {
 		return profile
 	}
 
-	for _, inc := range resp.Included {
+	// Interests are separate included records referenced from the
+	// attendee; tags, if any, live on the user.
+	interestIDs := make(map[string]bool, len(a.Relationships.Interests.Data))
+	for _, ref := range a.Relationships.Interests.Data {
+		interestIDs[ref.ID] = true
+	}
+	for _, inc := range included {
+		if inc.Type == "interest" && interestIDs[inc.ID] {
+			profile.Interests = appendTrimmed(profile.Interests, inc.Attributes.Name)
+		}
+	}
+
+	companyID := a.Relationships.Company.Data.ID
+	profile.Incomplete = true
+	for _, inc := range included {
 		if inc.Type != "user" || inc.ID != userID {
 			continue
 		}
+		profile.Incomplete = false
+		if companyID == "" {
+			companyID = inc.Relationships.Company.Data.ID
+		}
 
 		first := strings.TrimSpace(inc.Attributes.FirstName)
 		last := strings.TrimSpace(inc.Attributes.LastName)
 		name := strings.TrimSpace(strings.Join([]string{first, last}, " "))
 
-		location := ""
-		if len(inc.Attributes.CompanyCountries) > 0 {
-			location = strings.Join(inc.Attributes.CompanyCountries, ", ")
-		} else if inc.Attributes.TimeZone != "" {
-			location = inc.Attributes.TimeZone
+		for _, c := range inc.Attributes.CompanyCountries {
+			profile.Countries = appendTrimmed(profile.Countries, c)
 		}
 
 		profile.Name = name
 		profile.Title = inc.Attributes.CompanyTitle
 		profile.Company = inc.Attributes.CompanyName
-		profile.Location = location
 		profile.LinkedInURL = inc.Attributes.LinkedIn
+		profile.Twitter = NormalizeTwitter(inc.Attributes.Twitter)
+		profile.Website = strings.TrimSpace(inc.Attributes.Website)
+		profile.TimeZone = strings.TrimSpace(inc.Attributes.TimeZone)
+		profile.Email = normalizeEmail(inc.Attributes.Email)
+		for _, t := range inc.Attributes.Tags {
+			profile.Interests = appendTrimmed(profile.Interests, t)
+		}
+		applyFieldMap(&profile, fieldMap, inc)
 
 		break
 	}
 
+	// A company referenced from the attendee or its user adds what Brella
+	// knows about it; if it isn't included, the user's company name is all
+	// there is.
+	for _, inc := range included {
+		if companyID == "" || inc.Type != "company" || inc.ID != companyID {
+			continue
+		}
+		if profile.Company == "" {
+			profile.Company = strings.TrimSpace(inc.Attributes.Name)
+		}
+		profile.Industry = strings.TrimSpace(inc.Attributes.Industry)
+		profile.CompanySize = strings.TrimSpace(string(inc.Attributes.Size))
+		break
+	}
+
 	return profile
 }
+
+// appendTrimmed appends v, trimmed, to list unless it is empty or already
+// present.
+func appendTrimmed(list []string, v string) []string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return list
+	}
+	return appendUnique(list, v)
+}
+
+// normalizeEmail returns raw trimmed if it looks like an email address (an
+// @ followed by a domain containing a dot), or "" otherwise.
+func normalizeEmail(raw string) string {
+	email := strings.TrimSpace(raw)
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || strings.ContainsAny(email, " \t") {
+		return ""
+	}
+	domain := email[at+1:]
+	dot := strings.Index(domain, ".")
+	if dot <= 0 || dot == len(domain)-1 {
+		return ""
+	}
+	return email
+}
+
+// twitterHandlePattern matches a valid Twitter/X handle.
+var twitterHandlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+// NormalizeTwitter converts the forms attendees enter their Twitter account
+// in (@handle, bare handle, twitter.com or x.com URLs) into the canonical
+// https://twitter.com/<handle>. Values without a recognizable handle yield "".
+func NormalizeTwitter(raw string) string {
+	handle := strings.TrimSpace(raw)
+	if handle == "" {
+		return ""
+	}
+
+	lower := strings.ToLower(handle)
+	if strings.Contains(lower, "twitter.com") || strings.Contains(lower, "x.com") {
+		if !strings.Contains(handle, "://") {
+			handle = "https://" + handle
+		}
+		u, err := url.Parse(handle)
+		if err != nil {
+			return ""
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		host = strings.TrimPrefix(host, "mobile.")
+		if host != "twitter.com" && host != "x.com" {
+			return ""
+		}
+		path := u.Path
+		// Legacy hashbang links look like twitter.com/#!/handle.
+		if strings.HasPrefix(u.Fragment, "!/") {
+			path = strings.TrimPrefix(u.Fragment, "!")
+		}
+		handle, _, _ = strings.Cut(strings.Trim(path, "/"), "/")
+	}
+
+	handle = strings.TrimPrefix(handle, "@")
+	if !twitterHandlePattern.MatchString(handle) {
+		return ""
+	}
+	return "https://twitter.com/" + handle
+}
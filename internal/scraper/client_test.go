@@ -0,0 +1,399 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bitcoinconferencescraper/internal/scraper/brellatest"
+)
+
+// newTestClient returns a client for srv that retries twice with
+// millisecond backoff and logs nowhere.
+func newTestClient(srv *brellatest.Server) *Client {
+	c := NewClient(srv.URL, "token", srv.Client())
+	c.MaxRetries = 2
+	c.BaseRetryDelay = time.Millisecond
+	c.Logger = discardLogger()
+	return c
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestMapBrellaDetailToProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Profile
+	}{
+		{
+			name: "full record",
+			json: `{
+				"data": {"id": "a1", "type": "attendee",
+					"attributes": {"created-at": "2025-06-01T10:00:00Z"},
+					"relationships": {
+						"user": {"data": {"id": "u1", "type": "user"}},
+						"interests": {"data": [{"id": "i1", "type": "interest"}]}
+					}},
+				"included": [
+					{"id": "u1", "type": "user",
+						"attributes": {"first-name": " Ada ", "last-name": "Lovelace", "company-title": "CTO",
+							"company-name": "Engines", "linkedin": "https://linkedin.com/in/ada", "twitter": "@ada",
+							"email": " ada@example.com ", "time-zone": "Europe/London",
+							"company-countries": ["United Kingdom", " "], "tags": ["lightning"]},
+						"relationships": {"company": {"data": {"id": "c1", "type": "company"}}}},
+					{"id": "i1", "type": "interest", "attributes": {"name": "mining"}},
+					{"id": "c1", "type": "company", "attributes": {"name": "Engines Ltd", "industry": "Hardware", "size": 30}}
+				]}`,
+			want: Profile{
+				ID: "a1", Role: RoleAttendee, RecordType: "attendee",
+				RegisteredAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+				Name:         "Ada Lovelace", Title: "CTO", Company: "Engines",
+				LinkedInURL: "https://linkedin.com/in/ada", Twitter: "https://twitter.com/ada", Email: "ada@example.com",
+				TimeZone: "Europe/London", Countries: []string{"United Kingdom"},
+				Interests: []string{"mining", "lightning"}, Industry: "Hardware", CompanySize: "30",
+			},
+		},
+		{
+			name: "no user relationship",
+			json: `{"data": {"id": "a2", "type": "attendee"},
+				"included": [{"id": "u2", "type": "user", "attributes": {"first-name": "Not", "last-name": "Linked"}}]}`,
+			want: Profile{ID: "a2", Role: RoleAttendee, RecordType: "attendee"},
+		},
+		{
+			name: "empty included",
+			json: `{"data": {"id": "a3", "type": "attendee",
+				"relationships": {"user": {"data": {"id": "u3", "type": "user"}}}},
+				"included": []}`,
+			want: Profile{ID: "a3", Role: RoleAttendee, RecordType: "attendee", Incomplete: true},
+		},
+		{
+			name: "user missing from included",
+			json: `{"data": {"id": "a4", "type": "attendee",
+				"relationships": {"user": {"data": {"id": "u4", "type": "user"}}}},
+				"included": [{"id": "u5", "type": "user", "attributes": {"first-name": "Someone", "last-name": "Else"}}]}`,
+			want: Profile{ID: "a4", Role: RoleAttendee, RecordType: "attendee", Incomplete: true},
+		},
+		{
+			name: "time zone only",
+			json: `{"data": {"id": "a5", "type": "attendee",
+				"relationships": {"user": {"data": {"id": "u6", "type": "user"}}}},
+				"included": [{"id": "u6", "type": "user", "attributes": {"first-name": "Tz", "time-zone": "America/New_York"}}]}`,
+			want: Profile{ID: "a5", Role: RoleAttendee, RecordType: "attendee", Name: "Tz", TimeZone: "America/New_York"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp brellaAttendeeDetailResponse
+			if err := json.Unmarshal([]byte(tt.json), &resp); err != nil {
+				t.Fatal(err)
+			}
+			got := mapBrellaDetailToProfile(resp, nil)
+			if !profilesEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// profilesEqual compares profiles by their JSON, which treats nil and
+// empty slices alike, and by the fields JSON leaves out.
+func profilesEqual(a, b Profile) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb) && a.RecordType == b.RecordType && a.Incomplete == b.Incomplete
+}
+
+func TestProfileRegisteredAtJSON(t *testing.T) {
+	tests := []struct {
+		registeredAt time.Time
+		want         string
+	}{
+		{time.Time{}, ""},
+		{time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), `,"registered_at":"2025-06-01T10:00:00Z"`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(Profile{ID: "a1", RegisteredAt: tt.registeredAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"id":"a1","name":"","linkedin_url":""` + tt.want + "}"; string(b) != want {
+			t.Errorf("got %s, want %s", b, want)
+		}
+	}
+}
+
+func TestClientGetAttendeeProfileLocation(t *testing.T) {
+	srv := brellatest.NewServer("E", []brellatest.Attendee{
+		{ID: "a1", FirstName: "Ada", Countries: []string{"Finland", "Estonia"}, TimeZone: "Europe/Helsinki"},
+		{ID: "a2", FirstName: "Bob", TimeZone: "Europe/Helsinki"},
+		{ID: "a3", FirstName: "Cy"},
+	})
+	defer srv.Close()
+	c := newTestClient(srv)
+
+	for id, want := range map[string]string{"a1": "Finland, Estonia", "a2": "Europe/Helsinki", "a3": ""} {
+		p, err := c.GetAttendeeProfile(context.Background(), "E", id)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if p.Location != want {
+			t.Errorf("%s: Location = %q, want %q", id, p.Location, want)
+		}
+	}
+}
+
+func TestClientListProfilesPages(t *testing.T) {
+	var attendees []brellatest.Attendee
+	for _, id := range []string{"a1", "a2", "a3", "a4", "a5"} {
+		attendees = append(attendees, brellatest.Attendee{ID: id})
+	}
+	srv := brellatest.NewServer("E", attendees)
+	defer srv.Close()
+	c := newTestClient(srv)
+
+	var ids []string
+	for page := 1; ; page++ {
+		res, err := c.ListProfiles(context.Background(), "E", "", page, 2)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if res.Total != 5 {
+			t.Errorf("page %d: Total = %d, want 5", page, res.Total)
+		}
+		for _, p := range res.Profiles {
+			ids = append(ids, p.ID)
+		}
+		if !res.HasNext {
+			if page != 3 {
+				t.Errorf("HasNext false after page %d, want 3", page)
+			}
+			break
+		}
+		if page > 3 {
+			t.Fatal("HasNext never false")
+		}
+	}
+	if want := []string{"a1", "a2", "a3", "a4", "a5"}; !slices.Equal(ids, want) {
+		t.Errorf("listed %v, want %v", ids, want)
+	}
+}
+
+func TestClientErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  error
+		requests int
+	}{
+		{"retried server error", []int{http.StatusBadGateway}, nil, 2},
+		{"retried rate limit", []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, nil, 3},
+		{"retries run out", []int{500, 500, 500}, ErrUnexpectedStatus, 3},
+		{"not found", []int{http.StatusNotFound}, ErrNotFound, 1},
+		{"unauthorized", []int{http.StatusUnauthorized}, ErrUnauthorized, 1},
+		{"forbidden", []int{http.StatusForbidden}, ErrUnauthorized, 1},
+		{"rate limited after retries", []int{429, 429, 429}, ErrRateLimited, 3},
+		{"bad request", []int{http.StatusBadRequest}, ErrUnexpectedStatus, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brellatest.NewServer("E", []brellatest.Attendee{{ID: "a1", FirstName: "Ada"}})
+			defer srv.Close()
+			srv.Fail("/api/events/E/attendees/a1", tt.statuses...)
+			c := newTestClient(srv)
+
+			p, err := c.GetAttendeeProfile(context.Background(), "E", "a1")
+			if tt.wantErr == nil {
+				if err != nil || p.Name != "Ada" {
+					t.Errorf("got %+v, %v; want Ada", p, err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if n := len(srv.Requests()); n != tt.requests {
+				t.Errorf("%d requests, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRefreshAuthReportsNewTokens(t *testing.T) {
+	var refreshed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/auth/refresh":
+			refreshed.Store(true)
+			w.Header().Set("access-token", "new-access")
+			w.Header().Set("client", "new-client")
+			w.Header().Set("uid", "new-uid")
+		case r.Header.Get("access-token") != "new-access":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			io.WriteString(w, `{"data": {"id": "E", "type": "event"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", srv.Client())
+	c.AccessToken, c.ClientID, c.UID = "old-access", "old-client", "old-uid"
+	c.RefreshPath, c.RefreshToken = "/auth/refresh", "refresh"
+	c.Logger = discardLogger()
+	var got []string
+	c.OnTokenRefresh = func(accessToken, clientID, uid string) {
+		got = append(got, accessToken, clientID, uid)
+	}
+
+	if _, err := c.GetEvent(context.Background(), "E"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"new-access", "new-client", "new-uid"}; !refreshed.Load() || !slices.Equal(got, want) {
+		t.Errorf("OnTokenRefresh got %v, want %v", got, want)
+	}
+}
+
+func TestGetAttendeeProfileIncomplete(t *testing.T) {
+	srv := brellatest.NewServer("E", []brellatest.Attendee{
+		{ID: "a1", FirstName: "Ada", OmitUser: true},
+		{ID: "a2", FirstName: "Bob"},
+	})
+	defer srv.Close()
+	c := newTestClient(srv)
+
+	p, err := c.GetAttendeeProfile(context.Background(), "E", "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Incomplete || p.ID != "a1" || p.Name != "" {
+		t.Errorf("got %+v, want an incomplete profile with only its ID", p)
+	}
+	if got, want := srv.Requests(), []string{"/api/events/E/attendees/a1", "/api/events/E/attendees/a1"}; !slices.Equal(got, want) {
+		t.Errorf("requests %v, want %v (one retry)", got, want)
+	}
+
+	p, err = c.GetAttendeeProfile(context.Background(), "E", "a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Incomplete || p.Name != "Bob" {
+		t.Errorf("got %+v, want Bob's complete profile", p)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("%d requests in all, want 3 (no retry for a complete profile)", n)
+	}
+}
+
+func TestListProfilesSearchEncoding(t *testing.T) {
+	srv := brellatest.NewServer("E", []brellatest.Attendee{
+		{ID: "a1", Title: "Bitcoin Core developer"},
+		{ID: "a2", Company: "Smith & Sons"},
+		{ID: "a3", Company: "Café Økonomi"},
+		{ID: "a4", Company: "Other"},
+	})
+	defer srv.Close()
+	c := newTestClient(srv)
+
+	tests := []struct {
+		search, query string
+		want          []string
+	}{
+		{"bitcoin core", "search=bitcoin+core", []string{"a1"}},
+		{"smith & sons", "search=smith+%26+sons", []string{"a2"}},
+		{"café økonomi", "search=caf%C3%A9+%C3%B8konomi", []string{"a3"}},
+		{"a=b?c#d", "search=a%3Db%3Fc%23d", nil},
+		{"", "search=", []string{"a1", "a2", "a3", "a4"}},
+	}
+	for _, tt := range tests {
+		n := len(srv.Requests())
+		res, err := c.ListProfiles(context.Background(), "E", tt.search, 1, 50)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.search, err)
+		}
+		uri := srv.Requests()[n]
+		if !strings.HasSuffix(uri, "&"+tt.query) {
+			t.Errorf("%q: request %s, want it to end in &%s", tt.search, uri, tt.query)
+		}
+		if got := profileIDs(res.Profiles); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: listed %v, want %v", tt.search, got, tt.want)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	bases := []struct {
+		base, prefix string
+	}{
+		{"https://api.brella.io", "https://api.brella.io/"},
+		{"https://api.brella.io/", "https://api.brella.io/"},
+		{"https://host.example/api/v1", "https://host.example/api/v1/"},
+		{"https://host.example/api/v1/", "https://host.example/api/v1/"},
+	}
+	paths := []struct {
+		name, want string
+		path       func(c *Client) string
+	}{
+		{"refresh", "auth/refresh", func(c *Client) string { return c.RefreshPath }},
+		{"refresh without slash", "auth/refresh", func(*Client) string { return "auth/refresh" }},
+		{"attendee list", "api/events/E1/attendees?ignore_networking=true&order=newest&page[number]=2&page[size]=50&search=",
+			func(*Client) string {
+				return "/api/events/E1/attendees?ignore_networking=true&order=newest&page[number]=2&page[size]=50&search="
+			}},
+		{"attendee detail", "api/events/E1/attendees/a1", func(*Client) string { return "/api/events/E1/attendees/a1" }},
+		{"colon in first segment", "E:1/attendees", func(*Client) string { return "E:1/attendees" }},
+	}
+	for _, b := range bases {
+		for _, p := range paths {
+			c := &Client{BaseURL: b.base, RefreshPath: "/auth/refresh"}
+			got, err := c.endpoint(p.path(c))
+			if err != nil {
+				t.Errorf("%s with BaseURL %q: %v", p.name, b.base, err)
+				continue
+			}
+			if want := b.prefix + p.want; got != want {
+				t.Errorf("%s with BaseURL %q = %q, want %q", p.name, b.base, got, want)
+			}
+		}
+	}
+
+	for _, base := range []string{"", "://no-scheme"} {
+		if _, err := (&Client{BaseURL: base}).endpoint("/api/events/E1"); err == nil {
+			t.Errorf("endpoint with BaseURL %q succeeded, want an error", base)
+		}
+	}
+}
+
+// TestClientBasePath checks requests go under a BaseURL's base path,
+// through the actual list and detail calls.
+func TestClientBasePath(t *testing.T) {
+	srv := brellatest.NewServer("E1", testAttendees(2))
+	defer srv.Close()
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", srv.Config.Handler))
+	proxy := httptest.NewServer(mux)
+	defer proxy.Close()
+
+	for _, base := range []string{proxy.URL + "/api/v1", proxy.URL + "/api/v1/"} {
+		c := newTestClient(srv)
+		c.BaseURL = base
+		res, err := c.ListProfiles(context.Background(), "E1", "", 1, 50)
+		if err != nil {
+			t.Fatalf("ListProfiles with BaseURL %q: %v", base, err)
+		}
+		if len(res.Profiles) != 2 {
+			t.Fatalf("ListProfiles with BaseURL %q listed %d profiles, want 2", base, len(res.Profiles))
+		}
+		if _, err := c.GetAttendeeProfile(context.Background(), "E1", res.Profiles[0].ID); err != nil {
+			t.Errorf("GetAttendeeProfile with BaseURL %q: %v", base, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package scraper
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ProfileDiff is what changed between two sets of profiles, as computed by
+// DiffProfiles.
+type ProfileDiff struct {
+	// Added lists the profiles whose ID is only in the new set, in its
+	// order.
+	Added []Profile `json:"added"`
+	// Removed lists the profiles whose ID is only in the old set, in its
+	// order.
+	Removed []Profile `json:"removed"`
+	// Changed lists the profiles in both sets whose fields differ, in the
+	// new set's order.
+	Changed []ProfileChange `json:"changed"`
+	// Unkeyed counts profiles in either set without an ID, which can't be
+	// matched up and are left out of the diff.
+	Unkeyed int `json:"unkeyed,omitempty"`
+}
+
+// ProfileChange lists the changed fields of one profile.
+type ProfileChange struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange is one field's old and new value; a value is omitted when
+// the field was empty.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// profileFields lists the index and JSON name of each serialized Profile
+// field, in struct order.
+var profileFields = func() []profileField {
+	t := reflect.TypeFor[Profile]()
+	var fields []profileField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && name != "-" && name != "" {
+			fields = append(fields, profileField{index: i, name: name})
+		}
+	}
+	return fields
+}()
+
+type profileField struct {
+	index int
+	name  string
+}
+
+// DiffProfiles compares old and new profiles by ID, field by field. Empty
+// and missing values are the same (a nil list equals an empty one), times
+// are compared as instants, and list order matters. If an ID appears more
+// than once in a set, its first profile is used.
+func DiffProfiles(old, new []Profile) ProfileDiff {
+	var d ProfileDiff
+
+	oldByID := make(map[string]Profile, len(old))
+	for _, p := range old {
+		if p.ID == "" {
+			d.Unkeyed++
+			continue
+		}
+		if _, ok := oldByID[p.ID]; !ok {
+			oldByID[p.ID] = p
+		}
+	}
+
+	seen := make(map[string]bool, len(new))
+	for _, p := range new {
+		if p.ID == "" {
+			d.Unkeyed++
+			continue
+		}
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+
+		before, ok := oldByID[p.ID]
+		if !ok {
+			d.Added = append(d.Added, p)
+			continue
+		}
+		if fields := diffFields(before, p); len(fields) > 0 {
+			d.Changed = append(d.Changed, ProfileChange{ID: p.ID, Name: p.Name, Fields: fields})
+		}
+	}
+
+	for _, p := range old {
+		if p.ID != "" && !seen[p.ID] {
+			d.Removed = append(d.Removed, p)
+			seen[p.ID] = true // only the first of duplicates
+		}
+	}
+	return d
+}
+
+// diffFields returns the fields that differ between a and b, in struct
+// order.
+func diffFields(a, b Profile) []FieldChange {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	var changes []FieldChange
+	for _, f := range profileFields {
+		x, y := fieldValue(av.Field(f.index)), fieldValue(bv.Field(f.index))
+		if !sameValue(x, y) {
+			changes = append(changes, FieldChange{Field: f.name, Old: x, New: y})
+		}
+	}
+	return changes
+}
+
+// fieldValue returns v's value, or nil if it is empty.
+func fieldValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+	default:
+		if v.IsZero() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+// sameValue reports whether two fieldValue results are equal.
+func sameValue(x, y any) bool {
+	if tx, ok := x.(time.Time); ok {
+		ty, ok := y.(time.Time)
+		return ok && tx.Equal(ty)
+	}
+	return reflect.DeepEqual(x, y)
+}
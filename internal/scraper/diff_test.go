@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffProfiles(t *testing.T) {
+	old := []Profile{
+		{ID: "a1", Name: "Ada", Title: "CTO"},
+		{ID: "a2", Name: "Bob"},
+		{ID: "a3", Name: "Cy", LinkedInURL: "https://www.linkedin.com/in/cy"},
+		{Name: "No ID"},
+	}
+	new := []Profile{
+		{ID: "a4", Name: "Dee"},
+		{ID: "a3", Name: "Cy", LinkedInURL: "https://www.linkedin.com/in/cy"},
+		{ID: "a1", Name: "Ada", Title: "CEO", LinkedInURL: "https://www.linkedin.com/in/ada"},
+	}
+
+	d := DiffProfiles(old, new)
+	if got := profileIDs(d.Added); !slices.Equal(got, []string{"a4"}) {
+		t.Errorf("added %v, want [a4]", got)
+	}
+	if got := profileIDs(d.Removed); !slices.Equal(got, []string{"a2"}) {
+		t.Errorf("removed %v, want [a2]", got)
+	}
+	want := []ProfileChange{{ID: "a1", Name: "Ada", Fields: []FieldChange{
+		{Field: "title", Old: "CTO", New: "CEO"},
+		{Field: "linkedin_url", New: "https://www.linkedin.com/in/ada"},
+	}}}
+	if !reflect.DeepEqual(d.Changed, want) {
+		t.Errorf("changed %+v, want %+v", d.Changed, want)
+	}
+	if d.Unkeyed != 1 {
+		t.Errorf("unkeyed %d, want 1", d.Unkeyed)
+	}
+}
+
+func TestDiffProfilesSingleField(t *testing.T) {
+	registered := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		old, new Profile
+		want     []FieldChange
+	}{
+		{"title changed", Profile{Title: "CTO"}, Profile{Title: "CEO"}, []FieldChange{{Field: "title", Old: "CTO", New: "CEO"}}},
+		{"LinkedIn found", Profile{}, Profile{LinkedInURL: "https://www.linkedin.com/in/ada"},
+			[]FieldChange{{Field: "linkedin_url", New: "https://www.linkedin.com/in/ada"}}},
+		{"email cleared", Profile{Email: "ada@example.com"}, Profile{}, []FieldChange{{Field: "email", Old: "ada@example.com"}}},
+		{"interest added", Profile{Interests: []string{"mining"}}, Profile{Interests: []string{"mining", "lightning"}},
+			[]FieldChange{{Field: "interests", Old: []string{"mining"}, New: []string{"mining", "lightning"}}}},
+		{"list order", Profile{Countries: []string{"FI", "EE"}}, Profile{Countries: []string{"EE", "FI"}},
+			[]FieldChange{{Field: "countries", Old: []string{"FI", "EE"}, New: []string{"EE", "FI"}}}},
+		{"flag set", Profile{}, Profile{LinkedInSearched: true}, []FieldChange{{Field: "linkedin_searched", New: true}}},
+		{"nil and empty list", Profile{Countries: nil}, Profile{Countries: []string{}}, nil},
+		{"same instant", Profile{RegisteredAt: registered}, Profile{RegisteredAt: registered.In(time.FixedZone("EEST", 3*3600))}, nil},
+		{"not serialized", Profile{RecordType: "attendee"}, Profile{RecordType: "speaker"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.old.ID, tt.new.ID = "a1", "a1"
+			d := DiffProfiles([]Profile{tt.old}, []Profile{tt.new})
+			var got []FieldChange
+			if len(d.Changed) > 0 {
+				got = d.Changed[0].Fields
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes %+v, want %+v", got, tt.want)
+			}
+			if len(d.Added)+len(d.Removed) > 0 {
+				t.Errorf("added %v, removed %v, want neither", d.Added, d.Removed)
+			}
+		})
+	}
+}
+
+func TestDiffProfilesDuplicateIDs(t *testing.T) {
+	old := []Profile{{ID: "a1", Title: "first"}, {ID: "a1", Title: "second"}, {ID: "a2"}, {ID: "a2"}}
+	new := []Profile{{ID: "a1", Title: "first"}, {ID: "a1", Title: "other"}}
+
+	d := DiffProfiles(old, new)
+	if len(d.Changed) != 0 {
+		t.Errorf("changed %+v, want none: only the first of each ID counts", d.Changed)
+	}
+	if got := profileIDs(d.Removed); !slices.Equal(got, []string{"a2"}) {
+		t.Errorf("removed %v, want [a2] once", got)
+	}
+}
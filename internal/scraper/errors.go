@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors the Client's requests fail with, for errors.Is. A non-200
+// response is a *StatusError, which matches ErrUnexpectedStatus and,
+// depending on its status, ErrUnauthorized, ErrNotFound, or
+// ErrRateLimited.
+var (
+	// ErrUnexpectedStatus matches every non-200 response.
+	ErrUnexpectedStatus = errors.New("unexpected status")
+
+	// ErrUnauthorized matches 401 and 403 responses: the credentials are
+	// missing, expired, or don't give access to the event.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrNotFound matches 404 responses, such as for an unknown event or
+	// an attendee who has left it.
+	ErrNotFound = errors.New("not found")
+
+	// ErrRateLimited matches 429 responses, once retries have run out.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrDecode is wrapped by errors decoding a response body that isn't
+	// the JSON expected.
+	ErrDecode = errors.New("malformed API response")
+)
+
+// StatusError reports a non-200 API response.
+type StatusError struct {
+	Status int
+	// Body is the start of the response body, for the error message.
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Status, e.Body)
+}
+
+// Is matches e against the sentinel errors for its status.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrUnexpectedStatus:
+		return true
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	}
+	return false
+}
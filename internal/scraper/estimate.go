@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+)
+
+// RequestEstimate is how many requests a scrape is expected to make, as
+// worked out by EstimateRequests.
+type RequestEstimate struct {
+	// Entries is how many list entries the scrape covers.
+	Entries int
+	// ListRequests and DetailRequests are the list pages and the detail
+	// requests for those entries.
+	ListRequests   int
+	DetailRequests int
+	// Unknown lists the "event/role" lists whose total the API didn't
+	// report; they aren't counted.
+	Unknown []string
+}
+
+// Requests returns the total number of requests in e.
+func (e RequestEstimate) Requests() int {
+	return e.ListRequests + e.DetailRequests
+}
+
+// EstimateRequests works out how many requests ScrapeAllProfiles would
+// make with maxPages, from the total each list reports for a single
+// one-entry probe page, so an accidentally expensive scrape, such as one
+// listing a huge event a page of one at a time, can be caught before it
+// starts. It makes one request per event and role. The estimate covers
+// StartPage, maxPages, MaxProfiles, BatchSize, and ListOnly, but not what
+// Since, Filter, or a checkpoint would save, so it is an upper bound for
+// those. Retries, and the extra pages AutoPageSize lists after shrinking
+// the page size, aren't counted either.
+func (s Scraper) EstimateRequests(ctx context.Context, maxPages int) (RequestEstimate, error) {
+	var est RequestEstimate
+	if s.Client == nil {
+		return est, fmt.Errorf("scraper client is nil")
+	}
+	pageSize := s.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	pageSize = min(pageSize, MaxPageSize)
+	start := max(s.StartPage, 1)
+	roles := s.Roles
+	if len(roles) == 0 {
+		roles = []string{RoleAttendee}
+	}
+
+	for _, id := range s.events() {
+		for _, role := range roles {
+			probe := s
+			probe.EventID, probe.role, probe.PageSize = id, role, 1
+			if role == RoleSpeaker || role == RoleSponsor {
+				if _, ok := s.Client.(RoleLister); !ok {
+					return est, fmt.Errorf("scraper client can't list %ss", role)
+				}
+			}
+			res, err := probe.listPage(ctx, 1)
+			if err != nil {
+				return est, fmt.Errorf("event %s: probing %ss list: %w", id, role, err)
+			}
+			if res.Total <= 0 {
+				if res.HasNext {
+					est.Unknown = append(est.Unknown, id+"/"+role)
+				}
+				continue
+			}
+
+			entries := max(res.Total-(start-1)*pageSize, 0)
+			if maxPages > 0 {
+				entries = min(entries, maxPages*pageSize)
+			}
+			if s.MaxProfiles > 0 {
+				entries = min(entries, s.MaxProfiles)
+			}
+			est.Entries += entries
+			est.ListRequests += (entries + pageSize - 1) / pageSize
+			if role != RoleAttendee || s.ListOnly {
+				continue
+			}
+			if _, ok := s.Client.(BatchProfileGetter); ok && s.BatchSize > 1 {
+				est.DetailRequests += (entries + s.BatchSize - 1) / s.BatchSize
+			} else {
+				est.DetailRequests += entries
+			}
+		}
+	}
+	return est, nil
+}
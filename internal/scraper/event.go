@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event describes a Brella event, from its event endpoint.
+type Event struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	StartsAt time.Time `json:"starts_at,omitzero"`
+	EndsAt   time.Time `json:"ends_at,omitzero"`
+	TimeZone string    `json:"time_zone,omitempty"`
+	// Location is the venue and city, as far as Brella gives them.
+	Location string `json:"location,omitempty"`
+}
+
+// brellaEventResponse models the fields we read from the event endpoint.
+type brellaEventResponse struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Name      string `json:"name"`
+			StartTime string `json:"start-time"`
+			EndTime   string `json:"end-time"`
+			TimeZone  string `json:"time-zone"`
+			Location  string `json:"location"`
+			VenueName string `json:"venue-name"`
+			City      string `json:"city"`
+			Country   string `json:"country"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// GetEvent fetches an event's name, dates, and location:
+//
+//	GET /api/events/{eventID}
+//
+// Times Brella gives in a format other than RFC 3339 are left zero.
+func (c *Client) GetEvent(ctx context.Context, eventID string) (Event, error) {
+	if eventID == "" {
+		return Event{}, errors.New("eventID is empty")
+	}
+
+	resp, err := c.get(ctx, "/api/events/"+eventID)
+	if err != nil {
+		return Event{}, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp brellaEventResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return Event{}, fmt.Errorf("%w: decoding event response: %w", ErrDecode, err)
+	}
+
+	attrs := apiResp.Data.Attributes
+	event := Event{
+		ID:       apiResp.Data.ID,
+		Name:     strings.TrimSpace(attrs.Name),
+		TimeZone: attrs.TimeZone,
+		Location: strings.TrimSpace(attrs.Location),
+	}
+	if event.ID == "" {
+		event.ID = eventID
+	}
+	if t, err := time.Parse(time.RFC3339, attrs.StartTime); err == nil {
+		event.StartsAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, attrs.EndTime); err == nil {
+		event.EndsAt = t
+	}
+	if event.Location == "" {
+		var parts []string
+		for _, v := range []string{attrs.VenueName, attrs.City, attrs.Country} {
+			parts = appendTrimmed(parts, v)
+		}
+		event.Location = strings.Join(parts, ", ")
+	}
+	return event, nil
+}
@@ -0,0 +1,31 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"bitcoinconferencescraper/internal/scraper/brellatest"
+)
+
+func TestGetEventJSON(t *testing.T) {
+	srv := brellatest.NewServer("E", nil)
+	defer srv.Close()
+	srv.Event = brellatest.Event{Name: "Bitcoin 2025", StartsAt: time.Date(2025, 5, 27, 9, 0, 0, 0, time.UTC)}
+	c := newTestClient(srv)
+
+	event, err := c.GetEvent(context.Background(), "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Brella gave no end time, so ends_at is left out rather than written
+	// as 0001-01-01.
+	if want := `{"id":"E","name":"Bitcoin 2025","starts_at":"2025-05-27T09:00:00Z"}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldMapFields are the Profile fields Client.FieldMap can map, by their
+// JSON names.
+var FieldMapFields = []string{"name", "title", "company", "email", "linkedin_url", "twitter", "website", "time_zone"}
+
+// CheckFieldMap reports an error if m maps a field not in FieldMapFields
+// or maps a field to an empty attribute key.
+func CheckFieldMap(m map[string]string) error {
+	for field, key := range m {
+		if !slices.Contains(FieldMapFields, field) {
+			return fmt.Errorf("unknown profile field %q (want one of %s)", field, strings.Join(FieldMapFields, ", "))
+		}
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("profile field %q is mapped to an empty attribute key", field)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes inc as usual and also keeps its attributes by
+// key, for Client.FieldMap to look up attributes the struct doesn't know.
+func (inc *brellaIncluded) UnmarshalJSON(b []byte) error {
+	type plain brellaIncluded
+	if err := json.Unmarshal(b, (*plain)(inc)); err != nil {
+		return err
+	}
+	var raw struct {
+		Attributes map[string]json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	inc.rawAttributes = raw.Attributes
+	return nil
+}
+
+// attribute returns the attribute key of inc as trimmed text, or "" if it
+// is missing or isn't a string or number.
+func (inc brellaIncluded) attribute(key string) string {
+	b, ok := inc.rawAttributes[key]
+	if !ok {
+		return ""
+	}
+	var t brellaText
+	if err := json.Unmarshal(b, &t); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(t))
+}
+
+// applyFieldMap overrides the fields of p that m maps with the mapped
+// attributes of user, normalized as the built-in attributes are.
+// Attributes the user doesn't have, or has empty, leave the field as it
+// was.
+func applyFieldMap(p *Profile, m map[string]string, user brellaIncluded) {
+	for field, key := range m {
+		v := user.attribute(key)
+		switch field {
+		case "twitter":
+			v = NormalizeTwitter(v)
+		case "email":
+			v = normalizeEmail(v)
+		}
+		if v == "" {
+			continue
+		}
+		switch field {
+		case "name":
+			p.Name = v
+		case "title":
+			p.Title = v
+		case "company":
+			p.Company = v
+		case "email":
+			p.Email = v
+		case "linkedin_url":
+			p.LinkedInURL = v
+		case "twitter":
+			p.Twitter = v
+		case "website":
+			p.Website = v
+		case "time_zone":
+			p.TimeZone = v
+		}
+	}
+}
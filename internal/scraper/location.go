@@ -0,0 +1,58 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LocationStrategy selects how Profile.Location is derived from the
+// company countries and time zone Brella has for an attendee. Both are
+// also kept as they are in Profile.Countries and Profile.TimeZone, so no
+// strategy loses data.
+type LocationStrategy string
+
+const (
+	// LocationCountriesFirst uses the countries joined with ", ", or the
+	// time zone when there are none. It is the default.
+	LocationCountriesFirst LocationStrategy = "countries"
+
+	// LocationTimeZoneFirst uses the time zone, or the countries when the
+	// time zone is blank.
+	LocationTimeZoneFirst LocationStrategy = "timezone"
+
+	// LocationSeparate leaves Location blank, for consumers that read
+	// Countries and TimeZone themselves.
+	LocationSeparate LocationStrategy = "separate"
+)
+
+// ParseLocationStrategy parses a LocationStrategy name. Empty means
+// LocationCountriesFirst.
+func ParseLocationStrategy(s string) (LocationStrategy, error) {
+	switch ls := LocationStrategy(strings.ToLower(strings.TrimSpace(s))); ls {
+	case "":
+		return LocationCountriesFirst, nil
+	case LocationCountriesFirst, LocationTimeZoneFirst, LocationSeparate:
+		return ls, nil
+	}
+	return "", fmt.Errorf("unknown location strategy %q (want countries, timezone, or separate)", s)
+}
+
+// location returns the Location of p under s, from its Countries and
+// TimeZone.
+func (s LocationStrategy) location(p Profile) string {
+	countries := strings.Join(p.Countries, ", ")
+	switch s {
+	case LocationSeparate:
+		return ""
+	case LocationTimeZoneFirst:
+		if p.TimeZone != "" {
+			return p.TimeZone
+		}
+		return countries
+	default:
+		if countries != "" {
+			return countries
+		}
+		return p.TimeZone
+	}
+}
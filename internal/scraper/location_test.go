@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+
+	"bitcoinconferencescraper/internal/scraper/brellatest"
+)
+
+func TestParseLocationStrategy(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want LocationStrategy
+	}{
+		{"", LocationCountriesFirst},
+		{"  ", LocationCountriesFirst},
+		{"countries", LocationCountriesFirst},
+		{"TimeZone", LocationTimeZoneFirst},
+		{" separate ", LocationSeparate},
+	} {
+		got, err := ParseLocationStrategy(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLocationStrategy(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"country", "tz", "both"} {
+		if _, err := ParseLocationStrategy(in); err == nil {
+			t.Errorf("ParseLocationStrategy(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestLocationStrategy(t *testing.T) {
+	both := Profile{Countries: []string{"Finland", "Estonia"}, TimeZone: "Europe/Helsinki"}
+	countries := Profile{Countries: []string{"Finland"}}
+	timeZone := Profile{TimeZone: "Europe/Helsinki"}
+	// A Location already set, or other location-like fields, don't count:
+	// Location is always derived from Countries and TimeZone.
+	stale := Profile{Location: "Helsinki", Company: "Acme Oy", TimeZone: "Europe/Helsinki"}
+
+	for _, tt := range []struct {
+		strategy LocationStrategy
+		p        Profile
+		want     string
+	}{
+		{"", both, "Finland, Estonia"},
+		{LocationCountriesFirst, both, "Finland, Estonia"},
+		{LocationCountriesFirst, countries, "Finland"},
+		{LocationCountriesFirst, timeZone, "Europe/Helsinki"},
+		{LocationCountriesFirst, Profile{}, ""},
+		{LocationCountriesFirst, stale, "Europe/Helsinki"},
+		{LocationTimeZoneFirst, both, "Europe/Helsinki"},
+		{LocationTimeZoneFirst, countries, "Finland"},
+		{LocationTimeZoneFirst, timeZone, "Europe/Helsinki"},
+		{LocationTimeZoneFirst, Profile{}, ""},
+		{LocationSeparate, both, ""},
+		{LocationSeparate, stale, ""},
+	} {
+		if got := tt.strategy.location(tt.p); got != tt.want {
+			t.Errorf("%q.location(%+v) = %q, want %q", tt.strategy, tt.p, got, tt.want)
+		}
+	}
+}
+
+// TestClientLocationStrategy checks each strategy end to end, from the
+// company countries and time zone Brella returns. Blank countries are
+// dropped before they can win over the time zone.
+func TestClientLocationStrategy(t *testing.T) {
+	srv := brellatest.NewServer("E", []brellatest.Attendee{
+		{ID: "both", FirstName: "Ada", Countries: []string{"Finland", "Estonia"}, TimeZone: "Europe/Helsinki"},
+		{ID: "countries", FirstName: "Bob", Countries: []string{"Finland"}},
+		{ID: "tz", FirstName: "Cy", Countries: []string{" ", ""}, TimeZone: " Europe/Helsinki "},
+		{ID: "none", FirstName: "Di"},
+	})
+	defer srv.Close()
+
+	want := map[LocationStrategy]map[string]string{
+		LocationCountriesFirst: {"both": "Finland, Estonia", "countries": "Finland", "tz": "Europe/Helsinki", "none": ""},
+		LocationTimeZoneFirst:  {"both": "Europe/Helsinki", "countries": "Finland", "tz": "Europe/Helsinki", "none": ""},
+		LocationSeparate:       {"both": "", "countries": "", "tz": "", "none": ""},
+	}
+	for strategy, locations := range want {
+		c := newTestClient(srv)
+		c.Location = strategy
+		for id, loc := range locations {
+			p, err := c.GetAttendeeProfile(context.Background(), "E", id)
+			if err != nil {
+				t.Fatalf("%s %s: %v", strategy, id, err)
+			}
+			if p.Location != loc {
+				t.Errorf("%s %s: Location = %q, want %q", strategy, id, p.Location, loc)
+			}
+			if id == "both" && (len(p.Countries) != 2 || p.TimeZone != "Europe/Helsinki") {
+				t.Errorf("%s %s: Countries = %q, TimeZone = %q; want both kept", strategy, id, p.Countries, p.TimeZone)
+			}
+		}
+	}
+}
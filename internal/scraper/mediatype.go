@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultBrellaMediaType is the x-brella-media-type to send when
+// DetectMediaType can't tell which one the backend wants.
+const DefaultBrellaMediaType = "brella.latest"
+
+// MediaTypes are the x-brella-media-type versions DetectMediaType tries,
+// in order.
+var MediaTypes = []string{"brella.v4", DefaultBrellaMediaType}
+
+// DetectMediaType finds the first of MediaTypes the backend answers a
+// one-attendee page of eventID's list with as expected, sets
+// BrellaMediaType to it, and returns it. A version is rejected by a 4xx
+// other than 401, 403, 404, or 429, or by a response that isn't a JSON:API
+// list; those statuses, and any other failure, end the probe with an
+// error, leaving BrellaMediaType as it was. So does no version being
+// accepted. It must not run alongside other requests made through c.
+func (c *Client) DetectMediaType(ctx context.Context, eventID string) (string, error) {
+	if eventID == "" {
+		return "", errors.New("eventID is empty")
+	}
+
+	saved := c.BrellaMediaType
+	path := fmt.Sprintf("/api/events/%s/attendees?page[number]=1&page[size]=1", eventID)
+	for _, mediaType := range MediaTypes {
+		c.BrellaMediaType = mediaType
+		reason, err := c.probeMediaType(ctx, path)
+		if err != nil {
+			c.BrellaMediaType = saved
+			return "", fmt.Errorf("probing media type %s: %w", mediaType, err)
+		}
+		if reason == "" {
+			return mediaType, nil
+		}
+		c.logger().Debug("media type rejected", "media_type", mediaType, "reason", reason)
+	}
+	c.BrellaMediaType = saved
+	return "", fmt.Errorf("none of the media types %s was accepted", strings.Join(MediaTypes, ", "))
+}
+
+// probeMediaType requests path with the current BrellaMediaType and
+// returns why the response rejects it, or "" if it doesn't.
+func (c *Client) probeMediaType(ctx context.Context, path string) (string, error) {
+	resp, err := c.get(ctx, path)
+	var se *StatusError
+	switch {
+	case errors.As(err, &se) && se.Status >= 400 && se.Status < 500 && !errors.Is(err, ErrUnauthorized) && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrRateLimited):
+		return fmt.Sprintf("status %d", se.Status), nil
+	case errors.Is(err, ErrDecode):
+		return err.Error(), nil
+	case err != nil:
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+		return "response content type " + ct, nil
+	}
+	var list struct {
+		Data *[]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return "response isn't JSON: " + err.Error(), nil
+	}
+	if list.Data == nil {
+		return "response has no data list", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,247 @@
+package scraper
+
+import (
+	"strings"
+
+	"bitcoinconferencescraper/internal/fold"
+)
+
+// MergeProfiles combines existing and freshly scraped profiles, deduplicating
+// by ID. Existing profiles keep their order, with new IDs appended in the
+// order they were scraped. Where both sides have a profile, fresh field
+// values win only when they are non-empty, so manual edits to fields the
+// scrape doesn't fill (such as corrected LinkedIn URLs) survive. Profiles
+// without an ID are kept as they are.
+func MergeProfiles(existing, fresh []Profile) []Profile {
+	out := make([]Profile, 0, len(existing)+len(fresh))
+	index := make(map[string]int, len(existing))
+
+	for _, p := range existing {
+		if p.ID != "" {
+			if i, ok := index[p.ID]; ok {
+				out[i] = MergeProfile(out[i], p)
+				continue
+			}
+			index[p.ID] = len(out)
+		}
+		out = append(out, p)
+	}
+
+	for _, p := range fresh {
+		if p.ID != "" {
+			if i, ok := index[p.ID]; ok {
+				out[i] = MergeProfile(out[i], p)
+				continue
+			}
+			index[p.ID] = len(out)
+		}
+		out = append(out, p)
+	}
+
+	return out
+}
+
+// MergeProfile overlays the non-empty fields of fresh onto old. Event tags
+// are combined rather than replaced.
+func MergeProfile(old, fresh Profile) Profile {
+	merged := old
+
+	mergeString(&merged.Name, fresh.Name)
+	mergeString(&merged.Title, fresh.Title)
+	mergeString(&merged.Company, fresh.Company)
+	mergeString(&merged.Email, fresh.Email)
+	mergeString(&merged.Location, fresh.Location)
+	// A status belongs to the URL it was checked for.
+	if fresh.LinkedInURL != "" && fresh.LinkedInURL != old.LinkedInURL || fresh.LinkedInStatus != 0 {
+		merged.LinkedInStatus = fresh.LinkedInStatus
+	}
+	mergeString(&merged.LinkedInURL, fresh.LinkedInURL)
+	mergeString(&merged.Twitter, fresh.Twitter)
+	mergeString(&merged.Website, fresh.Website)
+	mergeString(&merged.TimeZone, fresh.TimeZone)
+	mergeString(&merged.Role, fresh.Role)
+	mergeString(&merged.Industry, fresh.Industry)
+	mergeString(&merged.CompanySize, fresh.CompanySize)
+
+	if !fresh.RegisteredAt.IsZero() {
+		merged.RegisteredAt = fresh.RegisteredAt
+	}
+	if len(fresh.PossibleLinkedInURLs) > 0 {
+		merged.PossibleLinkedInURLs = fresh.PossibleLinkedInURLs
+	}
+	if len(fresh.Countries) > 0 {
+		merged.Countries = fresh.Countries
+	}
+	if len(fresh.Interests) > 0 {
+		merged.Interests = fresh.Interests
+	}
+	merged.Sources = mergeSources(old, fresh)
+	merged.LinkedInSearched = old.LinkedInSearched || fresh.LinkedInSearched
+	merged.TwitterSearched = old.TwitterSearched || fresh.TwitterSearched
+	// Either side's user data fills in an incomplete profile.
+	merged.Incomplete = old.Incomplete && fresh.Incomplete
+
+	merged.EventIDs = nil
+	for _, id := range old.EventIDs {
+		merged.EventIDs = appendUnique(merged.EventIDs, id)
+	}
+	for _, id := range fresh.EventIDs {
+		merged.EventIDs = appendUnique(merged.EventIDs, id)
+	}
+
+	return merged
+}
+
+// DedupeAcrossEvents folds together profiles that describe the same person
+// at different events: those with the same ID, and those with the same name
+// (ignoring case and surrounding space) whose event tags don't overlap.
+// Namesakes at the same event are kept apart, since they are most likely
+// different people. The first occurrence keeps its position; later ones are
+// merged into it with MergeProfile.
+func DedupeAcrossEvents(profiles []Profile) []Profile {
+	out := make([]Profile, 0, len(profiles))
+	byID := make(map[string]int, len(profiles))
+	byName := make(map[string][]int, len(profiles))
+
+	for _, p := range profiles {
+		i, ok := byID[p.ID]
+		if p.ID == "" {
+			ok = false
+		}
+		name := strings.ToLower(strings.TrimSpace(p.Name))
+		if !ok && name != "" {
+			for _, j := range byName[name] {
+				if !sharesEvent(out[j], p) {
+					i, ok = j, true
+					break
+				}
+			}
+		}
+
+		if ok {
+			out[i] = MergeProfile(out[i], p)
+		} else {
+			i = len(out)
+			out = append(out, p)
+			if name != "" {
+				byName[name] = append(byName[name], i)
+			}
+		}
+		if p.ID != "" {
+			byID[p.ID] = i
+		}
+	}
+
+	return out
+}
+
+// DedupeByName folds together profiles with the same name and company,
+// both compared as unaccented lowercase words, so "José García" at
+// "Blockstream" and "Jose Garcia" at "blockstream" are one person whatever
+// their IDs. Profiles without a name are kept as they are. The first
+// occurrence keeps its position, ID, and spelling of the name and company;
+// later ones are merged into it with MergeProfile. LinkedIn URLs either
+// side had that didn't end up as the merged LinkedInURL are kept among its
+// PossibleLinkedInURLs.
+func DedupeByName(profiles []Profile) []Profile {
+	out := make([]Profile, 0, len(profiles))
+	index := make(map[string]int, len(profiles))
+
+	for _, p := range profiles {
+		name := strings.Join(fold.Words(p.Name), " ")
+		if name == "" {
+			out = append(out, p)
+			continue
+		}
+		key := name + "\x00" + strings.Join(fold.Words(p.Company), " ")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, p)
+			continue
+		}
+		merged := MergeProfile(out[i], p)
+		// Both spell the same name; keep the first, likely accented, one.
+		merged.Name, merged.Company = out[i].Name, out[i].Company
+		merged.PossibleLinkedInURLs = combinedCandidates(merged.LinkedInURL, out[i], p)
+		out[i] = merged
+	}
+
+	return out
+}
+
+// combinedCandidates returns the LinkedIn URLs and possible URLs of the
+// profiles, without repeats and without primary.
+func combinedCandidates(primary string, profiles ...Profile) []string {
+	var out []string
+	for _, p := range profiles {
+		for _, u := range append([]string{p.LinkedInURL}, p.PossibleLinkedInURLs...) {
+			if u != "" && u != primary {
+				out = appendUnique(out, u)
+			}
+		}
+	}
+	return out
+}
+
+// sharesEvent reports whether a and b are tagged with a common event.
+func sharesEvent(a, b Profile) bool {
+	for _, x := range a.EventIDs {
+		for _, y := range b.EventIDs {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeSources combines the Sources of old and fresh for MergeProfile. An
+// old entry is dropped when fresh has its own value for the field without
+// a source, since Brella now provides it.
+func mergeSources(old, fresh Profile) map[string]string {
+	var out map[string]string
+	set := func(field, source string) {
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[field] = source
+	}
+	for field, source := range old.Sources {
+		if fresh.Sources[field] == "" && sourcedValue(fresh, field) != "" {
+			continue
+		}
+		set(field, source)
+	}
+	for field, source := range fresh.Sources {
+		set(field, source)
+	}
+	return out
+}
+
+// sourcedValue returns the value of a field Sources can name.
+func sourcedValue(p Profile, field string) string {
+	switch field {
+	case "company":
+		return p.Company
+	case "title":
+		return p.Title
+	default:
+		return ""
+	}
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func mergeString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
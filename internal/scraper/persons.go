@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// personRecordTypes are the JSON:API record types treated as people. Brella
+// serves regular attendees as "attendee"; an empty type is assumed to be a
+// person too, since older responses and checkpoints don't carry one.
+var personRecordTypes = map[string]bool{
+	"":         true,
+	"attendee": true,
+	"speaker":  true,
+	"user":     true,
+	"person":   true,
+}
+
+// nonPersonWords are whole words that mark a name as belonging to a booth,
+// sponsor, staff account, or company rather than a person. They are
+// matched case-insensitively.
+var nonPersonWords = map[string]bool{
+	"admin":      true,
+	"booth":      true,
+	"corp":       true,
+	"crew":       true,
+	"exhibitor":  true,
+	"exhibitors": true,
+	"gmbh":       true,
+	"inc":        true,
+	"llc":        true,
+	"ltd":        true,
+	"organiser":  true,
+	"organisers": true,
+	"organizer":  true,
+	"organizers": true,
+	"sponsor":    true,
+	"sponsors":   true,
+	"staff":      true,
+	"support":    true,
+	"team":       true,
+}
+
+// IsPerson reports whether p looks like a real attendee rather than a
+// booth, sponsor, or staff account. See NonPersonReason for the rules.
+func IsPerson(p Profile) bool {
+	return NonPersonReason(p) == ""
+}
+
+// NonPersonReason returns why p looks like something other than a person,
+// or "" if it passes every check. A profile is treated as a non-person when
+// its record type isn't a person type, it has no name, its name is the
+// company's name, its name contains a word such as "Team" or "Booth", or
+// its name is a single all-caps word of three or more letters (a company
+// like "BITMAIN"; an all-caps full name such as "JOHN SMITH" is kept).
+func NonPersonReason(p Profile) string {
+	if !personRecordTypes[strings.ToLower(p.RecordType)] {
+		return fmt.Sprintf("record type %q", p.RecordType)
+	}
+
+	name := strings.TrimSpace(p.Name)
+	if name == "" {
+		return "no name"
+	}
+	if company := strings.TrimSpace(p.Company); company != "" && strings.EqualFold(name, company) {
+		return "name matches company"
+	}
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if nonPersonWords[strings.ToLower(w)] {
+			return fmt.Sprintf("name contains %q", w)
+		}
+	}
+
+	if len(words) == 1 && isAllCaps(words[0]) {
+		return "all-caps single-word name"
+	}
+
+	return ""
+}
+
+// isAllCaps reports whether s has at least three letters and none of them
+// are lowercase.
+func isAllCaps(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			return false
+		}
+		letters++
+	}
+	return letters >= 3
+}
@@ -0,0 +1,49 @@
+package scraper
+
+import "testing"
+
+func TestNonPersonReason(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Profile
+		want string
+	}{
+		{"sponsor record", Profile{Name: "Acme", RecordType: "sponsor"}, `record type "sponsor"`},
+		{"booth record", Profile{Name: "Jane Doe", RecordType: "Booth"}, `record type "Booth"`},
+		{"no name", Profile{RecordType: "attendee"}, "no name"},
+		{"blank name", Profile{Name: "   "}, "no name"},
+		{"name is company", Profile{Name: "Blockstream", Company: "blockstream"}, "name matches company"},
+		{"team", Profile{Name: "River Team"}, `name contains "Team"`},
+		{"booth", Profile{Name: "Booth #12"}, `name contains "Booth"`},
+		{"staff", Profile{Name: "Conference STAFF"}, `name contains "STAFF"`},
+		{"legal form", Profile{Name: "Foo Mining, LLC"}, `name contains "LLC"`},
+		{"organizer", Profile{Name: "Bitcoin2025-organizers"}, `name contains "organizers"`},
+		{"all caps company", Profile{Name: "BITMAIN"}, "all-caps single-word name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NonPersonReason(tt.p); got != tt.want {
+				t.Errorf("NonPersonReason(%+v) = %q, want %q", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonPersonReasonKeepsPeople(t *testing.T) {
+	for _, p := range []Profile{
+		{Name: "Ada Lovelace", Company: "Analytical Engines", RecordType: "attendee"},
+		{Name: "JOHN SMITH"},
+		{Name: "Al"},
+		{Name: "BJ"},
+		{Name: "Adam Back", RecordType: "speaker"},
+		{Name: "Teamo Supremo"},
+		{Name: "José O'Brien-García", RecordType: "user"},
+		{Name: "Satoshi", Company: "Satoshi Labs"},
+		{Name: "田中 太郎"},
+		{Name: "Incy Wincy"},
+	} {
+		if reason := NonPersonReason(p); reason != "" {
+			t.Errorf("NonPersonReason(%+v) = %q, want a person", p, reason)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"bitcoinconferencescraper/internal/bodylimit"
+	"bitcoinconferencescraper/internal/breaker"
+	"bitcoinconferencescraper/internal/decompress"
+	"bitcoinconferencescraper/internal/metrics"
+)
+
+// ErrRetryBudgetExhausted is wrapped by request errors that weren't retried
+// because the client's RetryBudget was used up.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// maxRetryDelay caps the exponential backoff between attempts.
+const maxRetryDelay = 30 * time.Second
+
+// get issues a GET request for path and returns the response once the API
+// answers 200 OK. Rate limits (429), server errors (5xx), and transient
+// network errors (see isRetryable) are retried up to MaxRetries times with
+// exponential backoff and jitter, within RetryBudget. Anything else fails
+// immediately, except that a 401 triggers one token refresh and retry when
+// RefreshPath is configured. The caller must close the returned response
+// body.
+func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	refreshed := false
+
+	for attempt := 0; ; attempt++ {
+		// A stopped run (Ctrl-C or --max-runtime) issues no further
+		// requests, even with nothing to wait on below.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := c.waitActiveHours(ctx); err != nil {
+			return nil, err
+		}
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if c.Throttle != nil {
+			if err := c.Throttle.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.allowRequest(path); err != nil {
+			return nil, err
+		}
+
+		_, _, _, authGen := c.authHeaders()
+
+		resp, retryAfter, err := c.attempt(ctx, path)
+		c.recordOutcome(ctx, err)
+		if err == nil {
+			return resp, nil
+		}
+
+		var se *StatusError
+		if errors.As(err, &se) && se.Status == http.StatusUnauthorized && c.RefreshPath != "" && !refreshed {
+			refreshed = true
+			if rerr := c.refreshAuth(ctx, authGen); rerr != nil {
+				return nil, fmt.Errorf("%w (token refresh failed: %v)", err, rerr)
+			}
+			// The retry after a refresh doesn't count against MaxRetries.
+			attempt--
+			continue
+		}
+		if ctx.Err() != nil || !isRetryable(err) {
+			return nil, err
+		}
+
+		if attempt >= c.MaxRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+			}
+			return nil, err
+		}
+		if !c.takeRetry() {
+			return nil, fmt.Errorf("%w (%d retries): %w", ErrRetryBudgetExhausted, c.RetryBudget, err)
+		}
+
+		delay := c.retryDelay(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		c.logger().Warn("request failed, retrying", "path", path, "err", err, "delay", delay, "retry", attempt+1, "max_retries", c.MaxRetries)
+		c.count(metrics.Retries, "endpoint", endpointLabel(path))
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// takeRetry uses up one retry of RetryBudget, reporting false if none are
+// left. The first request to find it spent logs a warning.
+func (c *Client) takeRetry() bool {
+	if c.RetryBudget <= 0 {
+		return true
+	}
+	used := c.retriesUsed.Add(1)
+	if used == int64(c.RetryBudget)+1 {
+		c.logger().Warn("global retry budget hit; failing requests instead of retrying", "retry_budget", c.RetryBudget)
+	}
+	return used <= int64(c.RetryBudget)
+}
+
+// attempt makes a single GET request for path, bounded by RequestTimeout
+// if set. Non-200 responses are returned as a *StatusError along with any
+// Retry-After delay the server asked for.
+func (c *Client) attempt(ctx context.Context, path string) (*http.Response, time.Duration, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.count(metrics.HTTPRequests, "endpoint", endpointLabel(path), "status", "error")
+		cancel()
+		return nil, 0, err
+	}
+	c.count(metrics.HTTPRequests, "endpoint", endpointLabel(path), "status", strconv.Itoa(resp.StatusCode))
+	c.observeRateLimit(resp)
+
+	if err := decompress.Body(resp); err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, 0, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		cancel()
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &StatusError{
+			Status: resp.StatusCode,
+			Body:   strings.TrimSpace(string(body)),
+		}
+	}
+
+	// The attempt's timeout also covers reading the body, so it is
+	// released only once the caller closes it.
+	resp.Body = cancelOnClose{ReadCloser: bodylimit.Wrap(resp.Body, c.MaxResponseBytes), cancel: cancel}
+	return resp, 0, nil
+}
+
+// waitActiveHours blocks until ActiveHours is open, if it is set, or until
+// ctx is done.
+func (c *Client) waitActiveHours(ctx context.Context) error {
+	if c.ActiveHours == nil {
+		return nil
+	}
+	wait := c.ActiveHours.Until(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	resume := time.Now().Add(wait).Truncate(time.Second)
+	if old := c.pausedUntil.Load(); old != resume.Unix() && c.pausedUntil.CompareAndSwap(old, resume.Unix()) {
+		c.logger().Info("outside active hours; pausing requests", "active_hours", c.ActiveHours.String(), "resume_at", resume.In(c.ActiveHours.Loc), "wait", wait.Round(time.Second))
+	}
+	return sleepContext(ctx, wait)
+}
+
+// observeRateLimit feeds resp's rate-limit headers to the throttle.
+func (c *Client) observeRateLimit(resp *http.Response) {
+	if c.Throttle == nil {
+		return
+	}
+	if pause := c.Throttle.Observe(resp.Header); pause > 0 {
+		c.logger().Warn("rate limit exhausted, pausing until reset", "pause", pause)
+	} else {
+		c.logger().Debug("rate limit pacing", "delay", c.Throttle.Delay())
+	}
+}
+
+// count increments a counter on Metrics, if set.
+func (c *Client) count(name string, labels ...string) {
+	if c.Metrics != nil {
+		c.Metrics.Inc(name, labels...)
+	}
+}
+
+// endpointLabel names the Brella endpoint path belongs to, for metrics:
+// "attendees" for the list, "attendee" for a detail request,
+// "attendee_batch" for the list filtered by ID, "speakers" and "sponsors"
+// for those lists, "event" for the event itself.
+func endpointLabel(path string) string {
+	path, query, _ := strings.Cut(path, "?")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[1] == "events":
+		return "event"
+	case len(parts) == 4 && parts[3] == "attendees" && strings.Contains(query, "filter[id]="):
+		return "attendee_batch"
+	case len(parts) == 4 && parts[3] == "attendees":
+		return "attendees"
+	case len(parts) == 4 && (parts[3] == "speakers" || parts[3] == "sponsors"):
+		return parts[3]
+	case len(parts) == 5 && parts[3] == "attendees":
+		return "attendee"
+	}
+	return "other"
+}
+
+// allowRequest consults the circuit breaker before an attempt.
+func (c *Client) allowRequest(path string) error {
+	state, wait, err := c.Breaker.Allow()
+	if err != nil {
+		if wait > 0 {
+			return fmt.Errorf("%w: next probe in %s", err, wait.Round(time.Millisecond))
+		}
+		return fmt.Errorf("%w: probe in progress", err)
+	}
+	if state == breaker.HalfOpen {
+		c.logger().Info("circuit breaker half-open, probing", "path", path)
+	}
+	return nil
+}
+
+// recordOutcome reports an attempt's result to the circuit breaker and
+// logs any state change. Responses the backend answered without a 429 or
+// 5xx count as successes.
+func (c *Client) recordOutcome(ctx context.Context, err error) {
+	if c.Breaker == nil {
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		c.Breaker.Release()
+		return
+	}
+
+	from, to := c.Breaker.Record(err != nil && isRetryable(err))
+	if from == to {
+		return
+	}
+	switch to {
+	case breaker.Open:
+		c.logger().Warn("circuit breaker open; failing requests fast", "from", from, "cooldown", c.Breaker.CurrentCooldown(), "err", err)
+	case breaker.Closed:
+		c.logger().Info("circuit breaker closed; backend recovered")
+	}
+}
+
+// isRetryable reports whether a failed attempt is worth retrying: rate
+// limits and server errors are, and so are transient network errors
+// (timeouts, including per-attempt ones, reset connections, responses cut
+// off mid-stream, and DNS lookups that may pass next time). Other statuses
+// such as auth failures are not, nor are other errors: a host that doesn't
+// exist, a TLS certificate that doesn't verify, a body that can't be
+// decoded, or a bad BaseURL fail the same way every time.
+func isRetryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return retryableStatus(se.Status)
+	}
+	if errors.Is(err, ErrDecode) {
+		// Checked first: a compressed body that fails to decode may wrap
+		// io.ErrUnexpectedEOF.
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// cancelOnClose releases a per-attempt context when the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay returns the backoff before retry number attempt+1: the base
+// delay doubled per attempt, with the upper half randomized so concurrent
+// workers don't retry in lockstep.
+func (c *Client) retryDelay(attempt int) time.Duration {
+	base := c.BaseRetryDelay
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+
+	d := base << attempt
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// sleepContext pauses for d, returning early with ctx's error if ctx is
+// done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+// Auth failures and other client errors are not.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// parseRetryAfter interprets a Retry-After header given either in seconds
+// or as an HTTP date. Unparseable or missing values yield 0.
+func parseRetryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,161 @@
+package scraper
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// failingTransport fails every round trip with err, counting them.
+type failingTransport struct {
+	err   error
+	calls atomic.Int32
+}
+
+func (t *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return nil, t.err
+}
+
+// timeoutError is a net.Error that timed out, like a dial or read
+// deadline.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &StatusError{Status: 502}, true},
+		{"rate limit", &StatusError{Status: 429}, true},
+		{"not found", &StatusError{Status: 404}, false},
+		{"unauthorized", &StatusError{Status: 401}, false},
+		{"timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, true},
+		{"deadline exceeded", os.ErrDeadlineExceeded, true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"unexpected EOF", fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF), true},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "brella.invalid", IsNotFound: true}, false},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "api.brella.io", IsTimeout: true}, true},
+		{"dns server failure", &net.DNSError{Err: "server misbehaving", Name: "api.brella.io", IsTemporary: true}, true},
+		{"unknown authority", &x509.UnknownAuthorityError{}, false},
+		{"hostname mismatch", x509.HostnameError{Host: "api.brella.io", Certificate: &x509.Certificate{}}, false},
+		{"decode", fmt.Errorf("%w: gzip: invalid header", ErrDecode), false},
+		{"bad base URL", errors.New(`client BaseURL: parse "://x": missing protocol scheme`), false},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int32
+	}{
+		{"timeout", timeoutError{}, 3},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, 3},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "brella.invalid", IsNotFound: true}, 1},
+		{"certificate", &x509.UnknownAuthorityError{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &failingTransport{err: tt.err}
+			c := NewClient("https://api.brella.test", "token", &http.Client{Transport: tr})
+			c.MaxRetries = 2
+			c.BaseRetryDelay = time.Millisecond
+			c.Logger = discardLogger()
+
+			if _, err := c.get(context.Background(), "/api/events/E"); err == nil {
+				t.Fatal("get succeeded")
+			}
+			if n := tr.calls.Load(); n != tt.attempts {
+				t.Errorf("%d attempts, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestGetFailsFast(t *testing.T) {
+	t.Run("TLS verification", func(t *testing.T) {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+		srv.StartTLS()
+		defer srv.Close()
+		// The default client doesn't trust the test server's certificate.
+		c := NewClient(srv.URL, "token", &http.Client{Transport: &http.Transport{}})
+		c.MaxRetries = 2
+		c.BaseRetryDelay = time.Hour
+		c.Logger = discardLogger()
+
+		_, err := c.get(context.Background(), "/api/events/E")
+		var authErr x509.UnknownAuthorityError
+		if !errors.As(err, &authErr) {
+			t.Errorf("err = %v, want an unknown authority error", err)
+		}
+		if strings.Contains(fmt.Sprint(err), "giving up") {
+			t.Errorf("err = %v, want no retries", err)
+		}
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write([]byte("not gzip"))
+		}))
+		defer srv.Close()
+		c := NewClient(srv.URL, "token", srv.Client())
+		c.MaxRetries = 2
+		c.BaseRetryDelay = time.Hour
+		c.Logger = discardLogger()
+
+		_, err := c.get(context.Background(), "/api/events/E")
+		if !errors.Is(err, ErrDecode) {
+			t.Errorf("err = %v, want ErrDecode", err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("%d requests, want 1", n)
+		}
+	})
+
+	t.Run("bad base URL", func(t *testing.T) {
+		tr := &failingTransport{}
+		c := NewClient("://no-scheme", "token", &http.Client{Transport: tr})
+		c.MaxRetries = 2
+		c.BaseRetryDelay = time.Hour
+		c.Logger = discardLogger()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := c.get(ctx, "/api/events/E"); err == nil || ctx.Err() != nil {
+			t.Errorf("err = %v, want a BaseURL error without waiting to retry", err)
+		}
+		if n := tr.calls.Load(); n != 0 {
+			t.Errorf("%d round trips, want 0", n)
+		}
+	})
+}
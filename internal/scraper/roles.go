@@ -0,0 +1,211 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Roles a profile can be scraped in, for Profile.Role and Scraper.Roles.
+const (
+	RoleAttendee = "attendee"
+	RoleSpeaker  = "speaker"
+	RoleSponsor  = "sponsor"
+)
+
+// ParseRoles parses a comma-separated list of roles such as
+// "attendees,speakers", accepting singular or plural names, and returns
+// them as Role constants without repeats, in the order given.
+func ParseRoles(s string) ([]string, error) {
+	var roles []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		role := strings.TrimSuffix(name, "s")
+		switch role {
+		case RoleAttendee, RoleSpeaker, RoleSponsor:
+		default:
+			return nil, fmt.Errorf("unknown role %q (want attendees, speakers, or sponsors)", name)
+		}
+		roles = appendUnique(roles, role)
+	}
+	return roles, nil
+}
+
+// RoleLister is implemented by listers that can list an event's speakers
+// and sponsors besides its attendees. Unlike the attendee list, these
+// lists carry each record in full, so the profiles they return need no
+// detail requests. *Client implements it.
+type RoleLister interface {
+	ListSpeakers(ctx context.Context, eventID string, page, pageSize int) (ListProfilesResult, error)
+	ListSponsors(ctx context.Context, eventID string, page, pageSize int) (ListProfilesResult, error)
+}
+
+// brellaSpeakersListResponse is a page of the speakers endpoint.
+type brellaSpeakersListResponse struct {
+	Data     []brellaSpeaker  `json:"data"`
+	Included []brellaIncluded `json:"included"`
+	Meta     *brellaListMeta  `json:"meta"`
+}
+
+// brellaSpeaker is a speaker record. Its name, title, and company are its
+// own attributes, and it may reference the speaker's user and interests
+// like an attendee record does.
+type brellaSpeaker struct {
+	brellaAttendee
+
+	Attributes struct {
+		Name      string `json:"name"`
+		FirstName string `json:"first-name"`
+		LastName  string `json:"last-name"`
+		Title     string `json:"title"`
+		Company   string `json:"company"`
+		LinkedIn  string `json:"linkedin"`
+		Twitter   string `json:"twitter"`
+		Website   string `json:"website"`
+	} `json:"attributes"`
+}
+
+// brellaSponsorsListResponse is a page of the sponsors endpoint.
+type brellaSponsorsListResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Type       string `json:"type"`
+		Attributes struct {
+			Name     string `json:"name"`
+			Website  string `json:"website"`
+			LinkedIn string `json:"linkedin"`
+			Twitter  string `json:"twitter"`
+			Email    string `json:"email"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Meta *brellaListMeta `json:"meta"`
+}
+
+// ListSpeakers lists a page of an event's speakers:
+//
+//	GET /api/events/{eventID}/speakers
+//	    ?include=user,interests
+//	    &page[number]={page}
+//	    &page[size]={pageSize}
+//
+// Profiles have Role RoleSpeaker and IDs prefixed with "speaker-", so they
+// never collide with attendee IDs; a speaker who also registered as an
+// attendee is listed under both. Fields the speaker record leaves blank
+// are taken from its user, if included. HasNext and Total work as in
+// ListProfiles.
+func (c *Client) ListSpeakers(ctx context.Context, eventID string, page, pageSize int) (ListProfilesResult, error) {
+	if eventID == "" {
+		return ListProfilesResult{}, errors.New("eventID is empty")
+	}
+
+	path := fmt.Sprintf("/api/events/%s/speakers?include=user,interests&page[number]=%d&page[size]=%d", eventID, page, pageSize)
+
+	resp, err := c.get(ctx, path)
+	if err != nil {
+		return ListProfilesResult{}, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp brellaSpeakersListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return ListProfilesResult{}, fmt.Errorf("%w: decoding speakers response: %w", ErrDecode, err)
+	}
+
+	profiles := make([]Profile, 0, len(apiResp.Data))
+	for _, sp := range apiResp.Data {
+		if sp.ID == "" {
+			continue
+		}
+		p := mapBrellaSpeaker(sp, apiResp.Included, c.FieldMap)
+		p.Location = c.Location.location(p)
+		profiles = append(profiles, p)
+	}
+	return listResult(profiles, len(apiResp.Data), page, pageSize, apiResp.Meta), nil
+}
+
+// ListSponsors lists a page of an event's sponsors:
+//
+//	GET /api/events/{eventID}/sponsors
+//	    ?page[number]={page}
+//	    &page[size]={pageSize}
+//
+// Each sponsor is a company, so its profile has the sponsor's name as both
+// Name and Company. Profiles have Role RoleSponsor and IDs prefixed with
+// "sponsor-". HasNext and Total work as in ListProfiles.
+func (c *Client) ListSponsors(ctx context.Context, eventID string, page, pageSize int) (ListProfilesResult, error) {
+	if eventID == "" {
+		return ListProfilesResult{}, errors.New("eventID is empty")
+	}
+
+	path := fmt.Sprintf("/api/events/%s/sponsors?page[number]=%d&page[size]=%d", eventID, page, pageSize)
+
+	resp, err := c.get(ctx, path)
+	if err != nil {
+		return ListProfilesResult{}, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp brellaSponsorsListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return ListProfilesResult{}, fmt.Errorf("%w: decoding sponsors response: %w", ErrDecode, err)
+	}
+
+	profiles := make([]Profile, 0, len(apiResp.Data))
+	for _, sp := range apiResp.Data {
+		if sp.ID == "" {
+			continue
+		}
+		name := strings.TrimSpace(sp.Attributes.Name)
+		profiles = append(profiles, Profile{
+			ID:          "sponsor-" + sp.ID,
+			Role:        RoleSponsor,
+			Name:        name,
+			Company:     name,
+			LinkedInURL: strings.TrimSpace(sp.Attributes.LinkedIn),
+			Twitter:     NormalizeTwitter(sp.Attributes.Twitter),
+			Website:     strings.TrimSpace(sp.Attributes.Website),
+			Email:       normalizeEmail(sp.Attributes.Email),
+			RecordType:  sp.Type,
+		})
+	}
+	return listResult(profiles, len(apiResp.Data), page, pageSize, apiResp.Meta), nil
+}
+
+// mapBrellaSpeaker converts speaker sp into a Profile, preferring its own
+// attributes over those of the user it references in included, which are
+// read with fieldMap.
+func mapBrellaSpeaker(sp brellaSpeaker, included []brellaIncluded, fieldMap map[string]string) Profile {
+	p := mapBrellaAttendee(sp.brellaAttendee, included, fieldMap)
+	p.ID = "speaker-" + sp.ID
+	p.Role = RoleSpeaker
+
+	attr := sp.Attributes
+	name := strings.TrimSpace(attr.Name)
+	if name == "" {
+		name = strings.TrimSpace(strings.TrimSpace(attr.FirstName) + " " + strings.TrimSpace(attr.LastName))
+	}
+	for _, f := range []struct {
+		dst *string
+		v   string
+	}{
+		{&p.Name, name},
+		{&p.Title, strings.TrimSpace(attr.Title)},
+		{&p.Company, strings.TrimSpace(attr.Company)},
+		{&p.LinkedInURL, strings.TrimSpace(attr.LinkedIn)},
+		{&p.Twitter, NormalizeTwitter(attr.Twitter)},
+		{&p.Website, strings.TrimSpace(attr.Website)},
+	} {
+		if f.v != "" {
+			*f.dst = f.v
+		}
+	}
+
+	// A missing user only matters if the speaker record didn't name them.
+	p.Incomplete = p.Incomplete && p.Name == ""
+	return p
+}
@@ -0,0 +1,97 @@
+package scraper
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+)
+
+// walkSample is walkPages for Sample: it lists only the pages holding
+// s.Sample randomly chosen entries of s.role's list and calls fn with each
+// such page cut down to its chosen entries, and with Total set to the
+// sample size. Entries are chosen by their index in the list's reported
+// total, so only the first page and the pages holding a chosen entry are
+// listed. Without a total, or with a page range set by start and maxPages,
+// the whole range is listed and the sample chosen from what it holds.
+func (s *Scraper) walkSample(ctx context.Context, start, maxPages int, fn func(page int, res ListProfilesResult) error) error {
+	rng := rand.New(rand.NewPCG(s.Seed, s.Seed))
+
+	first, firstPage, err := s.listOnePage(ctx, start)
+	if err != nil || firstPage == 0 {
+		return err
+	}
+
+	if first.Total <= 0 || start != 1 || maxPages > 0 || !first.HasNext {
+		pool := first.Profiles
+		if first.HasNext && maxPages != 1 {
+			err := s.walkPages(ctx, firstPage+1, max(maxPages-1, 0), func(page int, res ListProfilesResult) error {
+				pool = append(pool, res.Profiles...)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		n := min(s.Sample, len(pool))
+		indices := rng.Perm(len(pool))[:n]
+		slices.Sort(indices)
+		picked := make([]Profile, 0, n)
+		for _, i := range indices {
+			picked = append(picked, pool[i])
+		}
+		s.Logger.Info("sampled list", "role", s.role, "listed", len(pool), "sample", n)
+		return fn(firstPage, ListProfilesResult{Profiles: picked, Total: n})
+	}
+
+	n := min(s.Sample, first.Total)
+	indices := rng.Perm(first.Total)[:n]
+	slices.Sort(indices)
+	s.Logger.Info("sampling list", "role", s.role, "total", first.Total, "sample", n)
+
+	for len(indices) > 0 {
+		page := indices[0]/s.PageSize + 1
+		res, listed := first, firstPage
+		if page != firstPage {
+			var err error
+			if res, listed, err = s.listOnePage(ctx, page); err != nil {
+				return err
+			}
+			if listed == 0 {
+				// The list is shorter than its total said.
+				return nil
+			}
+		}
+
+		// The page may have been listed at a smaller page size, but it
+		// still starts at the same entry.
+		lo := (listed - 1) * s.PageSize
+		var picked []Profile
+		for len(indices) > 0 && indices[0] < lo+s.PageSize {
+			if i := indices[0] - lo; i < len(res.Profiles) {
+				picked = append(picked, res.Profiles[i])
+			}
+			indices = indices[1:]
+		}
+		if err := fn(listed, ListProfilesResult{Profiles: picked, Total: n}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOnePage lists page with walkPages, returning it and the number it
+// was listed as, which differs from page if the page size was lowered for
+// AutoPageSize. The number is 0 if the page came back empty.
+func (s *Scraper) listOnePage(ctx context.Context, page int) (ListProfilesResult, int, error) {
+	var res ListProfilesResult
+	listed := 0
+	err := s.walkPages(ctx, page, 1, func(page int, r ListProfilesResult) error {
+		// A lowered page size has walkPages list a second page to cover
+		// the same entries; those are left for later.
+		if listed == 0 {
+			res, listed = r, page
+		}
+		return nil
+	})
+	return res, listed, err
+}
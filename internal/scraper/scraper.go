@@ -2,84 +2,1043 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"bitcoinconferencescraper/internal/breaker"
+	"bitcoinconferencescraper/internal/jitter"
+	"bitcoinconferencescraper/internal/metrics"
 )
 
+// ProfileLister is the part of Client that Scraper depends on. *Client
+// implements it; a fake serving scripted pages and errors lets the paging
+// logic run without HTTP.
+type ProfileLister interface {
+	ListProfiles(ctx context.Context, eventID, search string, page, pageSize int) (ListProfilesResult, error)
+	GetAttendeeProfile(ctx context.Context, eventID, attendeeID string) (Profile, error)
+}
+
 // Scraper orchestrates high-level scraping logic using the Client.
 type Scraper struct {
-	Client              *Client
-	PageSize            int
-	EventID             string
+	Client ProfileLister
+
+	// PageSize is the number of profiles listed per page, at most
+	// MaxPageSize; larger values are lowered to it with a warning. Zero
+	// means 50.
+	PageSize int
+
+	// AutoPageSize halves PageSize, down to 10, whenever listing a page
+	// fails for a reason a smaller page might avoid (a 5xx, 400, 413, or
+	// 422 response, or a timeout, after the Client's own retries), and
+	// lists the same attendees again. The smaller size is kept for the
+	// rest of the scrape and recorded in the checkpoint; maxPages keeps
+	// covering the attendees it would have at the original size.
+	AutoPageSize bool
+
+	EventID              string
 	DelayBetweenRequests time.Duration
+
+	// DelayJitter randomizes DelayBetweenRequests: each pause is drawn
+	// uniformly from DelayBetweenRequests*(1±DelayJitter). Zero keeps the
+	// delay fixed; values are clamped to [0, 1].
+	DelayJitter float64
+
+	// EventIDs, if set, lists several events to scrape one after another
+	// and takes precedence over EventID. Each event gets its own page
+	// walk (maxPages and StartPage apply per event) and, when
+	// CheckpointPath is set, its own checkpoint file with the event ID
+	// inserted before the extension. The combined results are passed
+	// through DedupeAcrossEvents.
+	EventIDs []string
+
+	// Search, if set, is passed as the attendee list's search parameter so
+	// only attendees matching it are listed and fetched, which is much
+	// cheaper than scraping everyone and filtering afterwards. A checkpoint
+	// can only be resumed with the search it was written with.
+	Search string
+
+	// Roles lists what to scrape for each event, as Role constants:
+	// attendees, speakers, sponsors, or several of them, one after another
+	// in the given order. Empty means attendees only. Speakers and sponsors
+	// need a Client that is a RoleLister; their lists carry full records,
+	// so they cost one request per page and no detail requests. Search,
+	// Since, and BatchSize only apply to attendees. With CheckpointPath
+	// set, each role but attendees gets its own checkpoint file with the
+	// role inserted before the extension, like EventIDs.
+	Roles []string
+
+	// role is the role a per-role copy of the Scraper lists.
+	role string
+
+	// streamed counts the profiles a Stream scrape handed to OnProfile,
+	// shared by the per-event and per-role copies so MaxProfiles can cap
+	// them across all of them.
+	streamed *int
+
+	// StartPage is the first attendee list page to fetch. Values <= 1 start
+	// at the beginning. When resuming, scraping starts at whichever is later:
+	// StartPage or the page after the checkpoint's last completed one.
+	StartPage int
+
+	// Concurrency is the number of attendee detail requests allowed in
+	// flight at once within a page. DelayBetweenRequests is enforced across
+	// all workers combined. Values <= 1 fetch one attendee at a time.
+	Concurrency int
+
+	// BatchSize, if > 1 and Client is a BatchProfileGetter, fetches up to
+	// that many attendee details per request instead of one, each batch
+	// counting as a single request for DelayBetweenRequests. Attendees a
+	// batch leaves out or returns incomplete are fetched one at a time. If
+	// the API turns out not to support batches (ErrBatchUnsupported), the
+	// rest of the scrape fetches one at a time.
+	BatchSize int
+
+	// ListOnly skips the attendee detail requests: each listed attendee is
+	// collected as a stub with only its ID (and EventIDs) set, for a quick
+	// headcount or ID harvest. SkipNonPersons and Filter have nothing to go
+	// on and let stubs through. A checkpoint written this way records the
+	// stubs as Checkpoint.Unfetched, so resuming it without ListOnly
+	// fetches their details, with Concurrency and BatchSize, before
+	// listing on. Speakers and sponsors come in full either way.
+	ListOnly bool
+
+	// Stream bounds memory for events of any size. Profiles are handed to
+	// OnProfile and not kept, so ScrapeAllProfiles and Resume return only
+	// those restored from an older checkpoint, and the checkpoint records
+	// their IDs (Checkpoint.Fetched) instead of the profiles. The list is
+	// also read in a goroutine, up to a page ahead of the detail requests,
+	// so listing the next page overlaps with fetching the current one.
+	// MaxProfiles still caps the profiles streamed across all events and
+	// roles.
+	Stream bool
+
+	// Sample, if > 0, scrapes only that many randomly chosen entries of
+	// each event's list (or of each role's, with Roles), for a quick look
+	// at a representative slice of a new event. Only the pages holding
+	// them are listed when the API reports the list's total. Profiles
+	// dropped by Filter or SkipNonPersons shrink the sample. It can't be
+	// combined with CheckpointPath.
+	Sample int
+
+	// Seed seeds the random choice of Sample, so the same seed picks the
+	// same entries from an unchanged list.
+	Seed uint64
+
+	// MaxProfiles, if > 0, stops the scrape once that many profiles have
+	// been collected (counting any restored from a checkpoint, and across
+	// all events and roles, streamed or not), even in the middle of a
+	// page. Profiles dropped by Filter don't count.
+	MaxProfiles int
+
+	// CheckpointPath, if set, is where progress is flushed during a scrape
+	// so an interrupted run can be continued with Resume.
+	CheckpointPath string
+
+	// CheckpointEvery is the number of newly fetched profiles between
+	// checkpoint flushes. A checkpoint is always written when a page
+	// completes; if CheckpointEvery <= 0, that is the only flush point.
+	CheckpointEvery int
+
+	// Restarts is how many times Resume starts over from the checkpoint
+	// after a scrape fails (for example once the Client's own retries are
+	// exhausted), waiting RestartDelay, doubled after each restart, in
+	// between. Cancellation, rejected credentials (ErrUnauthorized), and
+	// an exhausted retry budget are never restarted. maxPages keeps
+	// counting from where the first attempt started, so restarts don't
+	// fetch past the original page range.
+	Restarts     int
+	RestartDelay time.Duration
+
+	// Since, if not zero, skips attendees who registered before it. The
+	// attendee list is ordered newest first, so paging stops after the
+	// first page that reaches an older attendee. Profiles without a
+	// registration time are kept.
+	Since time.Time
+
+	// ContinueOnError makes an attendee whose detail fetch fails (after the
+	// Client's own retries) be skipped instead of stopping the scrape. Each
+	// skipped attendee is logged and passed to OnFetchError. Cancellation,
+	// an open circuit breaker, and an exhausted retry budget still stop the
+	// scrape. Skipped attendees are not retried when resuming from a
+	// checkpoint past their page.
+	ContinueOnError bool
+
+	// OnFetchError, if set, is called with each attendee skipped under
+	// ContinueOnError. It is never called concurrently.
+	OnFetchError func(FetchError)
+
+	// SkipNonPersons drops profiles that IsPerson rejects, such as booth,
+	// sponsor, and staff accounts, before Filter and OnProfile see them.
+	SkipNonPersons bool
+
+	// OnProfile, if set, is called with each profile as soon as it has been
+	// fetched, for example to stream results to disk. It is never called
+	// concurrently. Returning an error aborts the scrape.
+	OnProfile func(Profile) error
+
+	// Filter, if set, is called with each fetched profile; profiles for
+	// which it returns false are dropped before OnProfile and are not
+	// returned. It is never called concurrently.
+	Filter func(Profile) bool
+
+	// Metrics, if set, counts collected profiles.
+	Metrics metrics.Recorder
+
+	// ProgressFunc, if set, is called after each attendee is fetched with
+	// the number of attendees processed so far (including any restored
+	// from a checkpoint and any dropped by Filter) and the event's total
+	// attendee count from the API's pagination metadata, or 0 when the
+	// total is unknown. It is never called concurrently.
+	ProgressFunc func(done, total int)
+
+	// Logger receives progress and diagnostic output. Per-attendee lines are
+	// logged at debug level, page summaries at info. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
 }
 
-// ScrapeAllProfiles walks over pages until there are no more or maxPages is reached.
-// If maxPages <= 0, it keeps going until the API reports no more pages.
+// ScrapeAllProfiles walks over pages, starting at StartPage, until there are
+// no more or maxPages pages have been fetched. maxPages counts pages fetched
+// by this call, not absolute page numbers, so StartPage 31 with maxPages 10
+// covers pages 31-40. If maxPages <= 0, it keeps going until the API reports
+// no more pages. Every profile is tagged with the event it was scraped from.
+//
+// On error, the profiles collected before the failure are returned alongside
+// the error so callers can persist partial results.
 func (s Scraper) ScrapeAllProfiles(ctx context.Context, maxPages int) ([]Profile, error) {
+	return s.eachEvent(func(s Scraper) ([]Profile, error) {
+		return s.eachRole(func(s Scraper) ([]Profile, error) {
+			return s.scrape(ctx, maxPages, Checkpoint{EventID: s.EventID, Search: s.Search})
+		})
+	})
+}
+
+// Resume loads the checkpoint at CheckpointPath and continues scraping from
+// the page after the last completed one, skipping attendees that were already
+// fetched. maxPages counts only the pages fetched by this call. If no
+// checkpoint exists yet, it behaves like ScrapeAllProfiles.
+func (s Scraper) Resume(ctx context.Context, maxPages int) ([]Profile, error) {
+	if s.CheckpointPath == "" {
+		return nil, fmt.Errorf("checkpoint path is empty")
+	}
+	return s.eachEvent(func(s Scraper) ([]Profile, error) {
+		return s.eachRole(func(s Scraper) ([]Profile, error) {
+			return s.resume(ctx, maxPages)
+		})
+	})
+}
+
+// resume is Resume for the single event s.EventID.
+func (s Scraper) resume(ctx context.Context, maxPages int) ([]Profile, error) {
+	s, err := s.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := LoadCheckpoint(s.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+	if cp.EventID != "" && cp.EventID != s.EventID {
+		return nil, fmt.Errorf("checkpoint %s is for event %s, not %s", s.CheckpointPath, cp.EventID, s.EventID)
+	}
+	if cp.EventID != "" && cp.Search != s.Search {
+		return nil, fmt.Errorf("checkpoint %s is for search %q, not %q", s.CheckpointPath, cp.Search, s.Search)
+	}
+	cp.EventID = s.EventID
+	cp.Search = s.Search
+	cp.rescale(s.PageSize)
+
+	if cp.LastCompletedPage > 0 || len(cp.Profiles) > 0 || len(cp.Unfetched) > 0 || len(cp.Fetched) > 0 {
+		s.Logger.Info("resuming from checkpoint", "path", s.CheckpointPath, "last_completed_page", cp.LastCompletedPage, "profiles", len(cp.Profiles), "streamed", len(cp.Fetched), "unfetched", len(cp.Unfetched))
+	}
+
+	lastPage := 0
+	if maxPages > 0 {
+		start := s.StartPage
+		if cp.LastCompletedPage >= start {
+			start = cp.LastCompletedPage + 1
+		}
+		lastPage = start + maxPages - 1
+	}
+
+	for restart := 0; ; restart++ {
+		profiles, err := s.scrape(ctx, maxPages, cp)
+		// With the retry budget spent or the credentials rejected, a
+		// restart would only fail again.
+		if err == nil || ctx.Err() != nil || restart >= s.Restarts || errors.Is(err, ErrRetryBudgetExhausted) || errors.Is(err, ErrUnauthorized) {
+			return profiles, err
+		}
+
+		delay := s.RestartDelay << restart
+		if delay < 0 || delay > maxRestartDelay {
+			delay = maxRestartDelay
+		}
+		// Restarting into an open circuit breaker would fail straight away.
+		if c, ok := s.Client.(*Client); ok {
+			if wait := c.Breaker.ProbeIn(); wait > delay {
+				delay = wait
+			}
+		}
+		s.Logger.Warn("scrape failed; restarting from checkpoint", "err", err, "restart", restart+1, "max_restarts", s.Restarts, "delay", delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return profiles, err
+		}
+
+		cp, err = LoadCheckpoint(s.CheckpointPath)
+		if err != nil {
+			return profiles, err
+		}
+		cp.EventID = s.EventID
+		cp.Search = s.Search
+		cp.rescale(s.PageSize)
+		if lastPage > 0 {
+			maxPages = max(lastPage-cp.LastCompletedPage, 1)
+		}
+	}
+}
+
+// maxRestartDelay caps the wait between Resume restarts.
+const maxRestartDelay = 10 * time.Minute
+
+// CountAttendees walks the attendee list pages exactly like
+// ScrapeAllProfiles but skips the per-attendee detail requests, returning
+// how many attendee IDs were listed. It is meant for sizing a scrape before
+// running it.
+func (s Scraper) CountAttendees(ctx context.Context, maxPages int) (int, error) {
+	count := 0
+	for _, id := range s.events() {
+		s.EventID = id
+		s, err := s.withDefaults()
+		if err != nil {
+			return count, err
+		}
+
+		err = s.walkPages(ctx, s.StartPage, maxPages, func(page int, res ListProfilesResult) error {
+			count += len(res.Profiles)
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// events returns the events to scrape: EventIDs if set, else EventID.
+func (s Scraper) events() []string {
+	if len(s.EventIDs) > 0 {
+		return s.EventIDs
+	}
+	return []string{s.EventID}
+}
+
+// FetchError records an attendee whose details couldn't be fetched.
+type FetchError struct {
+	EventID    string
+	AttendeeID string
+	Err        error
+}
+
+func (e FetchError) Error() string {
+	return fmt.Sprintf("event %s: attendee %s: %v", e.EventID, e.AttendeeID, e.Err)
+}
+
+func (e FetchError) Unwrap() error { return e.Err }
+
+// MaxPageSize is the largest Scraper.PageSize sent to Brella. Larger pages
+// make its list responses slow enough to time out, or get rejected.
+const MaxPageSize = 200
+
+// minAutoPageSize is the smallest page size AutoPageSize backs off to.
+const minAutoPageSize = 10
+
+// errProfileCap stops a scrape once MaxProfiles profiles are collected.
+var errProfileCap = errors.New("profile cap reached")
+
+// errSinceReached stops a scrape after the first page reaching attendees
+// older than Since.
+var errSinceReached = errors.New("since reached")
+
+// stopsRun reports whether err should end a scrape even with
+// ContinueOnError: the backend is failing too much for the next attendee to
+// fare better.
+func stopsRun(err error) bool {
+	return errors.Is(err, breaker.ErrOpen) || errors.Is(err, ErrRetryBudgetExhausted)
+}
+
+// eachEvent calls fn with a copy of s for every event in turn and
+// deduplicates the combined results. With several events, each copy gets a
+// per-event checkpoint path. The first error stops the loop; the
+// profiles collected so far are still returned with it.
+func (s Scraper) eachEvent(fn func(Scraper) ([]Profile, error)) ([]Profile, error) {
+	events := s.events()
+	if len(events) == 1 {
+		s.EventID = events[0]
+		return fn(s)
+	}
+
+	s.countStreamed()
+	streamedBefore := *s.streamed
+	var all []Profile
+	for _, id := range events {
+		es := s
+		es.EventID = id
+		if s.CheckpointPath != "" {
+			es.CheckpointPath = eventCheckpointPath(s.CheckpointPath, id)
+		}
+		if s.MaxProfiles > 0 {
+			n := len(all) + *s.streamed - streamedBefore
+			if n >= s.MaxProfiles {
+				break
+			}
+			es.MaxProfiles = s.MaxProfiles - n
+		}
+
+		profiles, err := fn(es)
+		all = append(all, profiles...)
+		if err != nil {
+			return DedupeAcrossEvents(all), fmt.Errorf("event %s: %w", id, err)
+		}
+	}
+	return DedupeAcrossEvents(all), nil
+}
+
+// eachRole calls fn with a copy of s for every role in Roles in turn and
+// combines the results, like eachEvent does for events. Copies for roles
+// other than attendees get a per-role checkpoint path.
+func (s Scraper) eachRole(fn func(Scraper) ([]Profile, error)) ([]Profile, error) {
+	roles := s.Roles
+	if len(roles) == 0 {
+		roles = []string{RoleAttendee}
+	}
+
+	s.countStreamed()
+	streamedBefore := *s.streamed
+	var all []Profile
+	for _, role := range roles {
+		rs := s
+		rs.role = role
+		if s.CheckpointPath != "" && role != RoleAttendee {
+			rs.CheckpointPath = eventCheckpointPath(s.CheckpointPath, role)
+		}
+		if s.MaxProfiles > 0 {
+			n := len(all) + *s.streamed - streamedBefore
+			if n >= s.MaxProfiles {
+				break
+			}
+			rs.MaxProfiles = s.MaxProfiles - n
+		}
+
+		profiles, err := fn(rs)
+		all = append(all, profiles...)
+		if err != nil {
+			return all, err
+		}
+	}
+	return all, nil
+}
+
+// countStreamed gives s a streamed counter if it has none yet, for the
+// copies eachEvent and eachRole make to share.
+func (s *Scraper) countStreamed() {
+	if s.streamed == nil {
+		s.streamed = new(int)
+	}
+}
+
+// withDefaults validates s and fills in defaults for unset fields.
+func (s Scraper) withDefaults() (Scraper, error) {
 	if s.Client == nil {
-		return nil, fmt.Errorf("scraper client is nil")
+		return s, fmt.Errorf("scraper client is nil")
 	}
 	if s.EventID == "" {
-		return nil, fmt.Errorf("event ID is empty")
+		return s, fmt.Errorf("event ID is empty")
 	}
 	if s.PageSize <= 0 {
 		s.PageSize = 50
 	}
+	if s.StartPage < 1 {
+		s.StartPage = 1
+	}
+	if s.Sample > 0 && s.CheckpointPath != "" {
+		return s, fmt.Errorf("a sample can't be checkpointed")
+	}
 	if s.DelayBetweenRequests < 0 {
 		s.DelayBetweenRequests = 0
 	}
-
-	var all []Profile
-	page := 1
-
-	for {
-		if maxPages > 0 && page > maxPages {
-			break
+	if s.Logger == nil {
+		s.Logger = slog.Default()
+	}
+	if s.PageSize > MaxPageSize {
+		s.Logger.Warn("page size too large; using the maximum", "page_size", s.PageSize, "max_page_size", MaxPageSize)
+		s.PageSize = MaxPageSize
+	}
+	switch s.role {
+	case "":
+		s.role = RoleAttendee
+	case RoleAttendee:
+	case RoleSpeaker, RoleSponsor:
+		if _, ok := s.Client.(RoleLister); !ok {
+			return s, fmt.Errorf("scraper client can't list %ss", s.role)
 		}
+	default:
+		return s, fmt.Errorf("unknown role %q", s.role)
+	}
+	return s, nil
+}
 
-		log.Printf("scraper: fetching page %d (page size %d)", page, s.PageSize)
+// walkPages lists pages of s.role's list starting at page start until the
+// API reports no more pages, a page comes back empty, or maxPages pages
+// have been fetched, calling fn with each non-empty page. An error from fn
+// stops the walk and is returned as is.
+func (s *Scraper) walkPages(ctx context.Context, start, maxPages int, fn func(page int, res ListProfilesResult) error) error {
+	for page, fetched := start, 0; maxPages <= 0 || fetched < maxPages; page, fetched = page+1, fetched+1 {
+		s.Logger.Debug("fetching page", "page", page, "page_size", s.PageSize, "role", s.role)
 
-		res, err := s.Client.ListProfiles(ctx, s.EventID, page, s.PageSize)
+		res, err := s.listPage(ctx, page)
+		for err != nil && ctx.Err() == nil && s.shrinkPageSize(page, err) {
+			// The same first attendee, in pages half the size.
+			page, fetched = 2*page-1, 2*fetched
+			if maxPages > 0 {
+				maxPages *= 2
+			}
+			res, err = s.listPage(ctx, page)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("listing profiles page %d: %w", page, err)
+			return fmt.Errorf("listing %ss page %d: %w", s.role, page, err)
 		}
 
 		if len(res.Profiles) == 0 {
-			log.Printf("scraper: page %d returned 0 attendees, stopping", page)
-			break
+			s.Logger.Info("page returned no profiles, stopping", "page", page, "role", s.role)
+			return nil
 		}
 
-		log.Printf("scraper: page %d returned %d attendee ids", page, len(res.Profiles))
+		s.Logger.Info("fetched page", "page", page, "role", s.role, "profiles", len(res.Profiles))
 
-		for _, stub := range res.Profiles {
-			if stub.ID == "" {
-				continue
+		if err := fn(page, res); err != nil {
+			return err
+		}
+
+		if !res.HasNext {
+			return nil
+		}
+	}
+	return nil
+}
+
+// streamPages is walkPages for Stream: a copy of s walks the pages in a
+// goroutine, listing up to one page ahead of fn, which is called on the
+// calling goroutine with s.PageSize set to the size the page was listed
+// with. An error from fn stops the walk and is returned as is.
+func (s *Scraper) streamPages(ctx context.Context, start, maxPages int, fn func(page int, res ListProfilesResult) error) error {
+	type listed struct {
+		page, size int
+		res        ListProfilesResult
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make(chan listed, 1)
+	walkErr := make(chan error, 1)
+	lister := *s
+	go func() {
+		defer close(pages)
+		walkErr <- lister.walkPages(ctx, start, maxPages, func(page int, res ListProfilesResult) error {
+			select {
+			case pages <- listed{page: page, size: lister.PageSize, res: res}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
 			}
+		})
+	}()
 
-			log.Printf("scraper: fetching attendee %s", stub.ID)
+	for p := range pages {
+		s.PageSize = p.size
+		if err := fn(p.page, p.res); err != nil {
+			// Stop the lister and wait for it, dropping what it listed.
+			cancel()
+			for range pages {
+			}
+			<-walkErr
+			return err
+		}
+	}
+	return <-walkErr
+}
+
+// shrinkPageSize halves s.PageSize for AutoPageSize after listing page
+// failed with err, reporting whether it did.
+func (s *Scraper) shrinkPageSize(page int, err error) bool {
+	if !s.AutoPageSize || s.PageSize%2 != 0 || s.PageSize/2 < minAutoPageSize {
+		return false
+	}
+	if !pageSizeError(err) {
+		return false
+	}
+	s.Logger.Warn("listing page failed; retrying with a smaller page size", "page", page, "page_size", s.PageSize, "new_page_size", s.PageSize/2, "err", err)
+	s.PageSize /= 2
+	return true
+}
+
+// pageSizeError reports whether a list request failing with err might
+// succeed with a smaller page: a timeout, an oversized or undecodable
+// response, or a status rejecting the request as too big, but not a
+// refusal for other reasons such as auth or rate limits.
+func pageSizeError(err error) bool {
+	if stopsRun(err) {
+		return false
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		switch se.Status {
+		case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+			return true
+		}
+		return se.Status >= 500
+	}
+	return true
+}
+
+// listPage lists one page of s.role's list.
+func (s Scraper) listPage(ctx context.Context, page int) (ListProfilesResult, error) {
+	switch s.role {
+	case RoleSpeaker:
+		return s.Client.(RoleLister).ListSpeakers(ctx, s.EventID, page, s.PageSize)
+	case RoleSponsor:
+		return s.Client.(RoleLister).ListSponsors(ctx, s.EventID, page, s.PageSize)
+	default:
+		return s.Client.ListProfiles(ctx, s.EventID, s.Search, page, s.PageSize)
+	}
+}
+
+// scrape runs the pagination loop starting from the state in cp.
+func (s Scraper) scrape(ctx context.Context, maxPages int, cp Checkpoint) ([]Profile, error) {
+	s, err := s.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	all := cp.Profiles
+	// Attendees a Stream scrape handed to OnProfile without keeping them.
+	fetched := cp.Fetched
+	defer func() {
+		if s.streamed != nil {
+			*s.streamed += len(fetched)
+		}
+	}()
+	seen := make(map[string]bool, len(all)+len(fetched))
+	for _, p := range all {
+		seen[p.ID] = true
+	}
+	for _, id := range fetched {
+		seen[id] = true
+	}
 
-			profile, err := s.Client.GetAttendeeProfile(ctx, s.EventID, stub.ID)
-			if err != nil {
-				return nil, fmt.Errorf("getting attendee %s: %w", stub.ID, err)
+	// Attendees a list-only scrape listed without fetching them: a
+	// list-only scrape carries them on as stubs, any other fetches them
+	// first.
+	listed := cp.Unfetched
+	unfetched := make(map[string]bool, len(listed))
+	stubs := make(map[string]bool)
+	for _, id := range listed {
+		switch {
+		case seen[id]:
+		case s.ListOnly:
+			all = append(all, Profile{ID: id, EventIDs: []string{s.EventID}})
+			stubs[id], seen[id] = true, true
+		default:
+			unfetched[id] = true
+		}
+	}
+
+	sinceFlush := 0
+	filtered := 0
+	nonPersons := 0
+	incomplete := 0
+	tooOld := 0
+	failed := 0
+	reachedSince := false
+	done := len(all) + len(fetched)
+	total := 0
+	limiter := jitter.New(s.DelayBetweenRequests, s.DelayJitter)
+	var batching atomic.Bool
+	if _, ok := s.Client.(BatchProfileGetter); ok && s.BatchSize > 1 {
+		batching.Store(true)
+	}
+
+	flush := func() {
+		if s.CheckpointPath == "" {
+			return
+		}
+		cp.Profiles, cp.Fetched, cp.Unfetched = all, fetched, nil
+		if len(stubs) > 0 {
+			cp.Profiles = make([]Profile, 0, len(all))
+			for _, p := range all {
+				if stubs[p.ID] {
+					cp.Unfetched = append(cp.Unfetched, p.ID)
+				} else {
+					cp.Profiles = append(cp.Profiles, p)
+				}
+			}
+		}
+		for _, id := range listed {
+			if unfetched[id] {
+				cp.Unfetched = append(cp.Unfetched, id)
 			}
+		}
+		if err := saveCheckpoint(s.CheckpointPath, cp); err != nil {
+			s.Logger.Warn("writing checkpoint failed", "path", s.CheckpointPath, "err", err)
+			return
+		}
+		sinceFlush = 0
+	}
 
+	capped := func() bool {
+		return s.MaxProfiles > 0 && len(all)+len(fetched) >= s.MaxProfiles
+	}
+	if capped() {
+		s.Logger.Info("max profiles already collected; nothing to fetch", "max_profiles", s.MaxProfiles)
+		return all, nil
+	}
+
+	collect := func(profile Profile) error {
+		// Workers already in flight when the cap was hit still deliver
+		// their profiles; drop them.
+		if capped() {
+			return errProfileCap
+		}
+
+		profile.EventIDs = appendUnique(profile.EventIDs, s.EventID)
+		delete(unfetched, profile.ID)
+		stub := stubs[profile.ID]
+
+		done++
+		if s.ProgressFunc != nil {
+			s.ProgressFunc(done, total)
+		}
+
+		if !s.Since.IsZero() && !profile.RegisteredAt.IsZero() && profile.RegisteredAt.Before(s.Since) {
+			s.Logger.Debug("attendee registered before --since, skipping", "attendee_id", profile.ID, "registered_at", profile.RegisteredAt)
+			tooOld++
+			reachedSince = true
+			return nil
+		}
+
+		// Sponsors are companies, asked for as such.
+		if s.SkipNonPersons && profile.Role != RoleSponsor && !stub {
+			if reason := NonPersonReason(profile); reason != "" {
+				s.Logger.Debug("attendee skipped as non-person", "attendee_id", profile.ID, "name", profile.Name, "reason", reason)
+				nonPersons++
+				return nil
+			}
+		}
+
+		if s.Filter != nil && !stub && !s.Filter(profile) {
+			s.Logger.Debug("attendee filtered out", "attendee_id", profile.ID)
+			filtered++
+			return nil
+		}
+
+		if s.OnProfile != nil {
+			if err := s.OnProfile(profile); err != nil {
+				return fmt.Errorf("handling attendee %s: %w", profile.ID, err)
+			}
+		}
+
+		if s.Stream && !stub {
+			fetched = append(fetched, profile.ID)
+		} else {
 			all = append(all, profile)
+		}
+		seen[profile.ID] = true
+		if s.Metrics != nil {
+			s.Metrics.Inc(metrics.ProfilesScraped)
+		}
+		if profile.Incomplete {
+			incomplete++
+		}
+
+		sinceFlush++
+		if s.CheckpointEvery > 0 && sinceFlush >= s.CheckpointEvery {
+			flush()
+		}
+		if capped() {
+			return errProfileCap
+		}
+		return nil
+	}
+
+	skip := func(id string, err error) {
+		s.Logger.Warn("fetching attendee failed; skipping it", "attendee_id", id, "err", err)
+		failed++
+		if s.OnFetchError != nil {
+			s.OnFetchError(FetchError{EventID: s.EventID, AttendeeID: id, Err: err})
+		}
+	}
+
+	start := s.StartPage
+	if cp.LastCompletedPage >= start {
+		start = cp.LastCompletedPage + 1
+	}
 
-			if s.DelayBetweenRequests > 0 {
-				time.Sleep(s.DelayBetweenRequests)
+	if len(unfetched) > 0 {
+		var ids []string
+		for _, id := range listed {
+			if unfetched[id] {
+				ids = append(ids, id)
 			}
 		}
+		s.Logger.Info("fetching attendees listed by a list-only scrape", "attendees", len(ids))
+		err = s.fetchDetails(ctx, ids, limiter, &batching, collect, skip)
+		if err == nil {
+			flush()
+		}
+	}
 
-		if !res.HasNext {
-			break
+	onPage := func(page int, res ListProfilesResult) error {
+		if res.Total > 0 {
+			total = res.Total
+		}
+
+		var pending []string
+		for _, stub := range res.Profiles {
+			if stub.ID == "" || seen[stub.ID] {
+				continue
+			}
+			if s.role != RoleAttendee {
+				// Already complete; there are no details to fetch.
+				if err := collect(stub); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.ListOnly {
+				stubs[stub.ID] = true
+				if err := collect(Profile{ID: stub.ID}); err != nil {
+					return err
+				}
+				continue
+			}
+			pending = append(pending, stub.ID)
 		}
 
-		page++
+		if err := s.fetchDetails(ctx, pending, limiter, &batching, collect, skip); err != nil {
+			return err
+		}
+
+		cp.LastCompletedPage, cp.PageSize = page, s.PageSize
+		flush()
+		if reachedSince {
+			return errSinceReached
+		}
+		return nil
+	}
+
+	switch {
+	case err != nil:
+	case s.Sample > 0:
+		err = s.walkSample(ctx, start, maxPages, onPage)
+	case s.Stream:
+		err = s.streamPages(ctx, start, maxPages, onPage)
+	default:
+		err = s.walkPages(ctx, start, maxPages, onPage)
+	}
+	if errors.Is(err, errSinceReached) {
+		s.Logger.Info("reached attendees registered before the since time, stopping", "since", s.Since)
+		err = nil
+	}
+	if errors.Is(err, errProfileCap) {
+		s.Logger.Warn("max profiles reached; stopping early, results are truncated", "max_profiles", s.MaxProfiles)
+		err = nil
+		flush()
+	}
+	if err != nil {
+		flush()
+		return all, err
 	}
 
-	log.Printf("scraper: finished, collected %d profiles", len(all))
+	s.Logger.Info("scrape finished", "role", s.role, "profiles", len(all), "streamed", len(fetched), "filtered_out", filtered, "non_persons", nonPersons, "incomplete", incomplete, "before_since", tooOld, "failed", failed)
 
 	return all, nil
 }
+
+// fetchDetails fetches the given attendees using up to s.Concurrency workers
+// and passes each profile to collect. While batching is set, workers take
+// up to s.BatchSize attendees at a time and fetch them in one request;
+// ErrBatchUnsupported clears it. With ContinueOnError, a failed fetch is
+// passed to skip instead (a failed batch is retried one attendee at a
+// time first). collect and skip are never called concurrently. The first
+// error, from a fetch or from collect, cancels the remaining workers and
+// is returned.
+func (s Scraper) fetchDetails(ctx context.Context, ids []string, limiter *jitter.Pacer, batching *atomic.Bool, collect func(Profile) error, skip func(id string, err error)) error {
+	size := 1
+	if batching.Load() {
+		size = s.BatchSize
+	}
+	var chunks [][]string
+	for chunk := range slices.Chunk(ids, size) {
+		chunks = append(chunks, chunk)
+	}
+
+	workers := s.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	// fetchOne fetches a single attendee, reporting false if the worker
+	// should stop.
+	fetchOne := func(id string) bool {
+		if err := limiter.Wait(ctx); err != nil {
+			fail(err)
+			return false
+		}
+
+		s.Logger.Debug("fetching attendee", "attendee_id", id)
+
+		profile, err := s.Client.GetAttendeeProfile(ctx, s.EventID, id)
+		if err != nil {
+			if s.ContinueOnError && ctx.Err() == nil && !stopsRun(err) {
+				mu.Lock()
+				skip(id, err)
+				mu.Unlock()
+				return true
+			}
+			fail(fmt.Errorf("getting attendee %s: %w", id, err))
+			return false
+		}
+
+		mu.Lock()
+		err = collect(profile)
+		mu.Unlock()
+		if err != nil {
+			fail(err)
+			return false
+		}
+		return true
+	}
+
+	// fetchBatch fetches chunk in one request and returns the attendees
+	// still to fetch one at a time, reporting false if the worker should
+	// stop.
+	fetchBatch := func(chunk []string) ([]string, bool) {
+		if err := limiter.Wait(ctx); err != nil {
+			fail(err)
+			return nil, false
+		}
+
+		s.Logger.Debug("fetching attendee batch", "attendees", len(chunk))
+
+		profiles, err := s.Client.(BatchProfileGetter).GetAttendeeProfiles(ctx, s.EventID, chunk)
+		switch {
+		case errors.Is(err, ErrBatchUnsupported):
+			if batching.CompareAndSwap(true, false) {
+				s.Logger.Warn("batch attendee fetch not supported; fetching attendees one at a time", "err", err)
+			}
+			return chunk, true
+		case err != nil:
+			if s.ContinueOnError && ctx.Err() == nil && !stopsRun(err) {
+				s.Logger.Warn("batch attendee fetch failed; fetching its attendees one at a time", "attendees", len(chunk), "err", err)
+				return chunk, true
+			}
+			fail(fmt.Errorf("getting attendees %s: %w", strings.Join(chunk, ","), err))
+			return nil, false
+		}
+
+		got := make(map[string]bool, len(profiles))
+		mu.Lock()
+		for _, profile := range profiles {
+			if profile.Incomplete || got[profile.ID] {
+				continue
+			}
+			got[profile.ID] = true
+			if err = collect(profile); err != nil {
+				break
+			}
+		}
+		mu.Unlock()
+		if err != nil {
+			fail(err)
+			return nil, false
+		}
+
+		var rest []string
+		for _, id := range chunk {
+			if !got[id] {
+				rest = append(rest, id)
+			}
+		}
+		if len(rest) > 0 {
+			s.Logger.Debug("batch left out attendees or returned them incomplete; fetching them one at a time", "attendees", len(rest))
+		}
+		return rest, true
+	}
+
+	queue := make(chan []string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range queue {
+				if len(chunk) > 1 && batching.Load() {
+					var ok bool
+					if chunk, ok = fetchBatch(chunk); !ok {
+						return
+					}
+				}
+				for _, id := range chunk {
+					if !fetchOne(id) {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, chunk := range chunks {
+		select {
+		case queue <- chunk:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,289 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"bitcoinconferencescraper/internal/scraper/brellatest"
+)
+
+// testAttendees returns n attendees with IDs a1..an, named after them.
+func testAttendees(n int) []brellatest.Attendee {
+	attendees := make([]brellatest.Attendee, n)
+	for i := range attendees {
+		id := fmt.Sprintf("a%d", i+1)
+		attendees[i] = brellatest.Attendee{ID: id, FirstName: "Attendee", LastName: id}
+	}
+	return attendees
+}
+
+func profileIDs(profiles []Profile) []string {
+	ids := make([]string, len(profiles))
+	for i, p := range profiles {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestScrapeAllProfilesServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageSize  int
+		startPage int
+		maxPages  int
+		want      []string
+	}{
+		{name: "every page", pageSize: 2, want: []string{"a1", "a2", "a3", "a4", "a5"}},
+		{name: "one page", pageSize: 10, want: []string{"a1", "a2", "a3", "a4", "a5"}},
+		{name: "page limit", pageSize: 2, maxPages: 2, want: []string{"a1", "a2", "a3", "a4"}},
+		{name: "page range", pageSize: 2, startPage: 2, maxPages: 1, want: []string{"a3", "a4"}},
+		{name: "past the end", pageSize: 2, startPage: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brellatest.NewServer("E", testAttendees(5))
+			defer srv.Close()
+			s := Scraper{
+				Client:    newTestClient(srv),
+				EventID:   "E",
+				PageSize:  tt.pageSize,
+				StartPage: tt.startPage,
+				Logger:    discardLogger(),
+			}
+
+			profiles, err := s.ScrapeAllProfiles(context.Background(), tt.maxPages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := profileIDs(profiles); !slices.Equal(got, tt.want) {
+				t.Errorf("scraped %v, want %v", got, tt.want)
+			}
+			for _, p := range profiles {
+				if p.Name != "Attendee "+p.ID || !slices.Equal(p.EventIDs, []string{"E"}) {
+					t.Errorf("profile %s = %+v, want its details and event", p.ID, p)
+				}
+			}
+		})
+	}
+}
+
+func TestScrapeAllProfilesServerErrors(t *testing.T) {
+	t.Run("list error", func(t *testing.T) {
+		srv := brellatest.NewServer("E", testAttendees(3))
+		defer srv.Close()
+		srv.Fail("/api/events/E/attendees", http.StatusForbidden)
+		s := Scraper{Client: newTestClient(srv), EventID: "E", Logger: discardLogger()}
+
+		_, err := s.ScrapeAllProfiles(context.Background(), 0)
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("detail error keeps earlier profiles", func(t *testing.T) {
+		srv := brellatest.NewServer("E", testAttendees(3))
+		defer srv.Close()
+		srv.Fail("/api/events/E/attendees/a2", http.StatusNotFound)
+		s := Scraper{Client: newTestClient(srv), EventID: "E", Logger: discardLogger()}
+
+		profiles, err := s.ScrapeAllProfiles(context.Background(), 0)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+		if got := profileIDs(profiles); !slices.Equal(got, []string{"a1"}) {
+			t.Errorf("returned %v, want [a1]", got)
+		}
+	})
+
+	t.Run("continue on error", func(t *testing.T) {
+		srv := brellatest.NewServer("E", testAttendees(3))
+		defer srv.Close()
+		srv.Fail("/api/events/E/attendees/a2", http.StatusNotFound)
+		var skipped []FetchError
+		s := Scraper{
+			Client:          newTestClient(srv),
+			EventID:         "E",
+			ContinueOnError: true,
+			OnFetchError:    func(e FetchError) { skipped = append(skipped, e) },
+			Logger:          discardLogger(),
+		}
+
+		profiles, err := s.ScrapeAllProfiles(context.Background(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := profileIDs(profiles); !slices.Equal(got, []string{"a1", "a3"}) {
+			t.Errorf("scraped %v, want [a1 a3]", got)
+		}
+		if len(skipped) != 1 || skipped[0].AttendeeID != "a2" || !errors.Is(skipped[0], ErrNotFound) {
+			t.Errorf("skipped %v, want a2 not found", skipped)
+		}
+	})
+
+	t.Run("retried server error", func(t *testing.T) {
+		srv := brellatest.NewServer("E", testAttendees(3))
+		defer srv.Close()
+		srv.Fail("/api/events/E/attendees", http.StatusBadGateway)
+		srv.Fail("/api/events/E/attendees/a3", http.StatusServiceUnavailable)
+		s := Scraper{Client: newTestClient(srv), EventID: "E", Logger: discardLogger()}
+
+		profiles, err := s.ScrapeAllProfiles(context.Background(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := profileIDs(profiles); !slices.Equal(got, []string{"a1", "a2", "a3"}) {
+			t.Errorf("scraped %v, want [a1 a2 a3]", got)
+		}
+	})
+}
+
+// fakeLister serves scripted list pages, by page number, and details
+// named after each ID, recording the pages listed.
+type fakeLister struct {
+	pages   map[int]ListProfilesResult
+	listErr map[int]error
+	getErr  map[string]error
+	listed  []int
+}
+
+func (f *fakeLister) ListProfiles(ctx context.Context, eventID, search string, page, pageSize int) (ListProfilesResult, error) {
+	f.listed = append(f.listed, page)
+	if err := f.listErr[page]; err != nil {
+		return ListProfilesResult{}, err
+	}
+	return f.pages[page], nil
+}
+
+func (f *fakeLister) GetAttendeeProfile(ctx context.Context, eventID, attendeeID string) (Profile, error) {
+	if err := f.getErr[attendeeID]; err != nil {
+		return Profile{}, err
+	}
+	return Profile{ID: attendeeID, Name: "Attendee " + attendeeID}, nil
+}
+
+// page returns a list page of stubs with ids.
+func page(hasNext bool, ids ...string) ListProfilesResult {
+	res := ListProfilesResult{HasNext: hasNext}
+	for _, id := range ids {
+		res.Profiles = append(res.Profiles, Profile{ID: id})
+	}
+	return res
+}
+
+func TestScrapeAllProfilesPaging(t *testing.T) {
+	errList := errors.New("list failed")
+	errGet := errors.New("detail failed")
+	tests := []struct {
+		name       string
+		pages      map[int]ListProfilesResult
+		listErr    map[int]error
+		getErr     map[string]error
+		maxPages   int
+		want       []string
+		wantListed []int
+		wantErr    error
+	}{
+		{
+			name:       "stops when HasNext is false",
+			pages:      map[int]ListProfilesResult{1: page(true, "a", "b"), 2: page(false, "c"), 3: page(false, "never")},
+			want:       []string{"a", "b", "c"},
+			wantListed: []int{1, 2},
+		},
+		{
+			name:       "stops on an empty page",
+			pages:      map[int]ListProfilesResult{1: page(true, "a", "b"), 2: page(true), 3: page(false, "never")},
+			want:       []string{"a", "b"},
+			wantListed: []int{1, 2},
+		},
+		{
+			name:       "maxPages caps the walk",
+			pages:      map[int]ListProfilesResult{1: page(true, "a"), 2: page(true, "b"), 3: page(true, "c")},
+			maxPages:   2,
+			want:       []string{"a", "b"},
+			wantListed: []int{1, 2},
+		},
+		{
+			name:       "list error is returned with earlier profiles",
+			pages:      map[int]ListProfilesResult{1: page(true, "a"), 3: page(false, "c")},
+			listErr:    map[int]error{2: errList},
+			want:       []string{"a"},
+			wantListed: []int{1, 2},
+			wantErr:    errList,
+		},
+		{
+			name:       "detail error stops the walk",
+			pages:      map[int]ListProfilesResult{1: page(true, "a", "b"), 2: page(false, "c")},
+			getErr:     map[string]error{"b": errGet},
+			want:       []string{"a"},
+			wantListed: []int{1},
+			wantErr:    errGet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := &fakeLister{pages: tt.pages, listErr: tt.listErr, getErr: tt.getErr}
+			s := Scraper{Client: lister, EventID: "E", Logger: discardLogger()}
+
+			profiles, err := s.ScrapeAllProfiles(context.Background(), tt.maxPages)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := profileIDs(profiles); !slices.Equal(got, tt.want) {
+				t.Errorf("scraped %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(lister.listed, tt.wantListed) {
+				t.Errorf("listed pages %v, want %v", lister.listed, tt.wantListed)
+			}
+		})
+	}
+}
+
+// TestScrapeAllProfilesStreamCap checks MaxProfiles caps a Stream scrape
+// across events and roles, not per event or role.
+func TestScrapeAllProfilesStreamCap(t *testing.T) {
+	srv := brellatest.NewServer("E", testAttendees(3))
+	defer srv.Close()
+	srv.Speakers = []brellatest.Attendee{{ID: "s1", FirstName: "Sam"}, {ID: "s2", FirstName: "Sue"}}
+
+	tests := []struct {
+		name string
+		s    Scraper
+		want []string
+	}{
+		{
+			name: "events",
+			s: Scraper{
+				Client:   &fakeLister{pages: map[int]ListProfilesResult{1: page(false, "a1", "a2", "a3")}},
+				EventIDs: []string{"E1", "E2"},
+			},
+			want: []string{"a1", "a2", "a3", "a1"},
+		},
+		{
+			name: "roles",
+			s:    Scraper{Client: newTestClient(srv), EventID: "E", Roles: []string{RoleAttendee, RoleSpeaker}},
+			want: []string{"a1", "a2", "a3", "speaker-s1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var streamed []string
+			s := tt.s
+			s.Stream, s.MaxProfiles, s.Logger = true, 4, discardLogger()
+			s.OnProfile = func(p Profile) error {
+				streamed = append(streamed, p.ID)
+				return nil
+			}
+
+			if _, err := s.ScrapeAllProfiles(context.Background(), 0); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(streamed, tt.want) {
+				t.Errorf("streamed %v, want %v", streamed, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"sort"
+	"strings"
+)
+
+// Summary aggregates a set of profiles for a quick overview of who is
+// attending.
+type Summary struct {
+	// Total is the number of profiles summarized.
+	Total int `json:"total"`
+
+	// Companies, Locations, and Countries count profiles per value,
+	// largest group first. Profiles without a value are left out of a
+	// grouping and counted in its Without field instead.
+	Companies Grouping `json:"companies"`
+	Locations Grouping `json:"locations"`
+	Countries Grouping `json:"countries"`
+}
+
+// Grouping is a count of profiles per value of one field.
+type Grouping struct {
+	Groups []Group `json:"groups"`
+	// Without counts profiles with no value for the field.
+	Without int `json:"without"`
+}
+
+// Group is one value of a Grouping and how many profiles have it.
+type Group struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Summarize counts profiles by company, location, and country. Values are
+// grouped ignoring case and surrounding space, and each group is labeled
+// with the first spelling seen. A profile listing several countries counts
+// once toward each of them. Groups are sorted by count, largest first, then
+// by value.
+func Summarize(profiles []Profile) Summary {
+	companies := newGroupCounter()
+	locations := newGroupCounter()
+	countries := newGroupCounter()
+
+	for _, p := range profiles {
+		companies.addProfile(p.Company)
+		locations.addProfile(p.Location)
+		countries.addProfile(p.Countries...)
+	}
+
+	return Summary{
+		Total:     len(profiles),
+		Companies: companies.grouping(),
+		Locations: locations.grouping(),
+		Countries: countries.grouping(),
+	}
+}
+
+// groupCounter tallies values case-insensitively, remembering the first
+// spelling of each.
+type groupCounter struct {
+	index   map[string]int
+	groups  []Group
+	without int
+}
+
+func newGroupCounter() *groupCounter {
+	return &groupCounter{index: make(map[string]int)}
+}
+
+// addProfile counts one profile toward each of its non-blank values, or
+// as a profile without a value if there are none.
+func (g *groupCounter) addProfile(values ...string) {
+	counted := false
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		counted = true
+
+		key := strings.ToLower(v)
+		if i, ok := g.index[key]; ok {
+			g.groups[i].Count++
+			continue
+		}
+		g.index[key] = len(g.groups)
+		g.groups = append(g.groups, Group{Value: v, Count: 1})
+	}
+	if !counted {
+		g.without++
+	}
+}
+
+func (g *groupCounter) grouping() Grouping {
+	groups := g.groups
+	if groups == nil {
+		groups = []Group{}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return strings.ToLower(groups[i].Value) < strings.ToLower(groups[j].Value)
+	})
+	return Grouping{Groups: groups, Without: g.without}
+}
@@ -1,5 +1,11 @@
 package scraper
 
+import "time"
+
+// SourceLinkedIn is the Profile.Sources value for fields read from a
+// LinkedIn search result.
+const SourceLinkedIn = "linkedin"
+
 // Profile represents a user profile from the Bitcoin Conference app.
 // Fields can be expanded as you discover them in the API responses.
 type Profile struct {
@@ -7,7 +13,63 @@ type Profile struct {
 	Name                 string   `json:"name"`
 	Title                string   `json:"title,omitempty"`
 	Company              string   `json:"company,omitempty"`
+	Email                string   `json:"email,omitempty"`
 	Location             string   `json:"location,omitempty"`
 	LinkedInURL          string   `json:"linkedin_url"`
 	PossibleLinkedInURLs []string `json:"possible_linkedin_urls,omitempty"`
+	Twitter              string   `json:"twitter,omitempty"`
+	Website              string   `json:"website,omitempty"`
+	TimeZone             string   `json:"time_zone,omitempty"`
+
+	// Industry and CompanySize describe the attendee's company, from the
+	// company record Brella includes with some attendees; CompanySize is
+	// Brella's bracket or count as given, such as "11-50".
+	Industry    string `json:"industry,omitempty"`
+	CompanySize string `json:"company_size,omitempty"`
+
+	// Role is what the profile was scraped as: RoleAttendee, RoleSpeaker,
+	// or RoleSponsor. Empty in files written before roles existed, which
+	// only held attendees.
+	Role string `json:"role,omitempty"`
+
+	// RegisteredAt is when the attendee registered for the event, from the
+	// attendee record's created-at; zero if Brella didn't say.
+	RegisteredAt time.Time `json:"registered_at,omitzero"`
+
+	// Countries lists the attendee's company countries as Brella returns
+	// them. Location is derived from Countries and TimeZone according to
+	// the Client's LocationStrategy.
+	Countries []string `json:"countries,omitempty"`
+	// Interests lists the interests and tags the attendee selected.
+	Interests []string `json:"interests,omitempty"`
+
+	// EventIDs lists the events the attendee was scraped from.
+	EventIDs []string `json:"event_ids,omitempty"`
+
+	// LinkedInSearched records that LinkedIn enrichment already searched
+	// for this profile, so reruns skip it even if nothing was found.
+	LinkedInSearched bool `json:"linkedin_searched,omitempty"`
+
+	// LinkedInStatus is the HTTP status LinkedInURL answered with when
+	// --verify-urls last checked it, such as 200, 404, or LinkedIn's 999
+	// for refused bots; 0 if it hasn't been checked.
+	LinkedInStatus int `json:"linkedin_status,omitempty"`
+
+	// TwitterSearched is LinkedInSearched for Twitter enrichment.
+	TwitterSearched bool `json:"twitter_searched,omitempty"`
+
+	// Sources records where fields that didn't come from Brella came
+	// from, keyed by JSON field name: {"company": "linkedin"} means
+	// Company was backfilled from LinkedIn. Fields not listed are
+	// Brella's.
+	Sources map[string]string `json:"sources,omitempty"`
+
+	// Incomplete marks a profile whose detail response referenced a user
+	// record it didn't include, so only the ID could be read. An attendee
+	// with no user at all is not incomplete, just empty.
+	Incomplete bool `json:"incomplete,omitempty"`
+
+	// RecordType is the JSON:API type of the attendee record, used by
+	// IsPerson. It is not persisted.
+	RecordType string `json:"-"`
 }
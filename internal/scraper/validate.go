@@ -0,0 +1,99 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidationError describes one problem ValidateProfiles found in a
+// profile.
+type ValidationError struct {
+	// Index is the profile's position in the validated slice.
+	Index int
+	ID    string
+	Field string
+	Issue string
+
+	// Warning marks problems worth a look that don't make the record
+	// unusable. Anything else is a hard error.
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	kind := "error"
+	if e.Warning {
+		kind = "warning"
+	}
+	return fmt.Sprintf("%s: profile %d (id %q): %s: %s", kind, e.Index, e.ID, e.Field, e.Issue)
+}
+
+// ValidateProfiles sanity-checks scraped profiles before they are used
+// downstream. Empty names, malformed LinkedIn URLs, and duplicate IDs are
+// hard errors; a batch where most records fail usually means the Brella
+// response format has drifted. Missing IDs and malformed possible LinkedIn
+// URLs are reported as warnings. All returned errors are
+// *ValidationError.
+func ValidateProfiles(profiles []Profile) []error {
+	var errs []error
+	add := func(i int, p Profile, field, issue string, warning bool) {
+		errs = append(errs, &ValidationError{Index: i, ID: p.ID, Field: field, Issue: issue, Warning: warning})
+	}
+
+	firstIndex := make(map[string]int, len(profiles))
+
+	for i, p := range profiles {
+		if p.ID == "" {
+			add(i, p, "id", "missing", true)
+		} else if first, ok := firstIndex[p.ID]; ok {
+			add(i, p, "id", fmt.Sprintf("duplicate of profile %d", first), false)
+		} else {
+			firstIndex[p.ID] = i
+		}
+
+		if strings.TrimSpace(p.Name) == "" {
+			add(i, p, "name", "empty", false)
+		}
+
+		if p.LinkedInURL != "" {
+			if issue := checkLinkedInURL(p.LinkedInURL); issue != "" {
+				add(i, p, "linkedin_url", issue, false)
+			}
+		}
+		for _, u := range p.PossibleLinkedInURLs {
+			if issue := checkLinkedInURL(u); issue != "" {
+				add(i, p, "possible_linkedin_urls", issue, true)
+			}
+		}
+	}
+
+	return errs
+}
+
+// HasHardErrors reports whether errs, as returned by ValidateProfiles,
+// contains anything other than warnings.
+func HasHardErrors(errs []error) bool {
+	for _, err := range errs {
+		if ve, ok := err.(*ValidationError); !ok || !ve.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLinkedInURL returns a description of what is wrong with raw as a
+// LinkedIn URL, or "" if it looks fine.
+func checkLinkedInURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Sprintf("unparseable URL %q", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Sprintf("URL %q is not http(s)", raw)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return fmt.Sprintf("URL %q is not on linkedin.com", raw)
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+// Package sheets writes rows to a Google Sheets spreadsheet using a service
+// account. It talks to the Sheets REST API directly so the rest of the tool
+// doesn't depend on the Google client libraries.
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const apiBase = "https://sheets.googleapis.com/v4/spreadsheets/"
+
+// Client writes to one sheet (tab) of a spreadsheet. The spreadsheet must
+// be shared with the service account's client_email.
+type Client struct {
+	httpClient    *http.Client
+	spreadsheetID string
+	sheet         string
+	creds         serviceAccount
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// New returns a Client for the given spreadsheet and sheet name, using the
+// service account key in credentialsJSON (the key file downloaded from the
+// Google Cloud console). An empty sheet means "Sheet1".
+func New(httpClient *http.Client, spreadsheetID, sheet string, credentialsJSON []byte) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if spreadsheetID == "" {
+		return nil, fmt.Errorf("spreadsheet ID is empty")
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	creds, err := parseServiceAccount(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		httpClient:    httpClient,
+		spreadsheetID: spreadsheetID,
+		sheet:         sheet,
+		creds:         creds,
+	}, nil
+}
+
+// Replace clears the sheet and writes header followed by rows, starting at
+// cell A1.
+func (c *Client) Replace(ctx context.Context, header []string, rows [][]string) error {
+	if err := c.call(ctx, http.MethodPost, c.sheetRange()+":clear", nil, struct{}{}, nil); err != nil {
+		return fmt.Errorf("clearing sheet: %w", err)
+	}
+
+	values := append([][]string{header}, rows...)
+	q := url.Values{"valueInputOption": {"RAW"}}
+	if err := c.call(ctx, http.MethodPut, c.sheetRange(), q, valueRange{Values: values}, nil); err != nil {
+		return fmt.Errorf("writing rows: %w", err)
+	}
+	return nil
+}
+
+// AppendNew appends the rows whose first cell (the ID) isn't already in
+// the sheet's first column, writing header first when the sheet is empty.
+// It returns the number of rows appended.
+func (c *Client) AppendNew(ctx context.Context, header []string, rows [][]string) (int, error) {
+	var existing valueRange
+	if err := c.call(ctx, http.MethodGet, c.sheetRange()+"!A:A", nil, nil, &existing); err != nil {
+		return 0, fmt.Errorf("reading existing IDs: %w", err)
+	}
+
+	seen := make(map[string]bool, len(existing.Values))
+	for _, row := range existing.Values {
+		if len(row) > 0 {
+			seen[row[0]] = true
+		}
+	}
+
+	var values [][]string
+	if len(existing.Values) == 0 {
+		values = append(values, header)
+	}
+	appended := 0
+	for _, row := range rows {
+		if len(row) > 0 && row[0] != "" && seen[row[0]] {
+			continue
+		}
+		values = append(values, row)
+		appended++
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	q := url.Values{"valueInputOption": {"RAW"}, "insertDataOption": {"INSERT_ROWS"}}
+	if err := c.call(ctx, http.MethodPost, c.sheetRange()+":append", q, valueRange{Values: values}, nil); err != nil {
+		return 0, fmt.Errorf("appending rows: %w", err)
+	}
+	return appended, nil
+}
+
+// valueRange is the Sheets API representation of a block of cells.
+type valueRange struct {
+	Values [][]string `json:"values"`
+}
+
+// sheetRange returns the A1 range covering the whole sheet, quoted so
+// sheet names with spaces work.
+func (c *Client) sheetRange() string {
+	return "'" + strings.ReplaceAll(c.sheet, "'", "''") + "'"
+}
+
+// call sends a Sheets API request for the values endpoint rng. in, if not
+// nil, is sent as the JSON body; out, if not nil, receives the decoded
+// response.
+func (c *Client) call(ctx context.Context, method, rng string, query url.Values, in, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	u := apiBase + url.PathEscape(c.spreadsheetID) + "/values/" + url.PathEscape(rng)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sheets API status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
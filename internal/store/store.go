@@ -0,0 +1,375 @@
+// Package store persists scraped profiles in a SQLite database so repeated
+// runs can update existing attendees, insert new ones, and track when each
+// was first and last seen.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Pure-Go SQLite driver; registers itself as "sqlite".
+	_ "modernc.org/sqlite"
+
+	"bitcoinconferencescraper/internal/scraper"
+)
+
+// Store is a SQLite-backed profile table keyed by Profile.ID.
+type Store struct {
+	db *sql.DB
+}
+
+// column maps one Profile field to a TEXT column. Slices are stored as
+// JSON arrays and true booleans as "1"; empty values are stored as "".
+type column struct {
+	name string
+	get  func(p scraper.Profile) (string, error)
+	set  func(p *scraper.Profile, v string) error
+
+	// update, if set, is the SQL expression Upsert assigns to an existing
+	// row's column, with %[1]s standing for the column name. By default a
+	// non-empty incoming value replaces the stored one.
+	update string
+}
+
+func text(name string, field func(p *scraper.Profile) *string) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			return *field(&p), nil
+		},
+		set: func(p *scraper.Profile, v string) error {
+			*field(p) = v
+			return nil
+		},
+	}
+}
+
+func list(name string, field func(p *scraper.Profile) *[]string) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			values := *field(&p)
+			if len(values) == 0 {
+				return "", nil
+			}
+			b, err := json.Marshal(values)
+			return string(b), err
+		},
+		set: func(p *scraper.Profile, v string) error {
+			if v == "" {
+				*field(p) = nil
+				return nil
+			}
+			return json.Unmarshal([]byte(v), field(p))
+		},
+	}
+}
+
+// sources stores a map as a JSON object, or "" when empty.
+func sources(name string, field func(p *scraper.Profile) *map[string]string) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			values := *field(&p)
+			if len(values) == 0 {
+				return "", nil
+			}
+			b, err := json.Marshal(values)
+			return string(b), err
+		},
+		set: func(p *scraper.Profile, v string) error {
+			if v == "" {
+				*field(p) = nil
+				return nil
+			}
+			return json.Unmarshal([]byte(v), field(p))
+		},
+	}
+}
+
+// timestamp stores a time as RFC 3339 in UTC, or "" for the zero time.
+func timestamp(name string, field func(p *scraper.Profile) *time.Time) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			t := *field(&p)
+			if t.IsZero() {
+				return "", nil
+			}
+			return t.UTC().Format(time.RFC3339), nil
+		},
+		set: func(p *scraper.Profile, v string) error {
+			if v == "" {
+				*field(p) = time.Time{}
+				return nil
+			}
+			t, err := time.Parse(time.RFC3339, v)
+			*field(p) = t
+			return err
+		},
+	}
+}
+
+// number stores an int in decimal, or "" for 0.
+func number(name string, field func(p *scraper.Profile) *int) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			if n := *field(&p); n != 0 {
+				return strconv.Itoa(n), nil
+			}
+			return "", nil
+		},
+		set: func(p *scraper.Profile, v string) error {
+			if v == "" {
+				*field(p) = 0
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			*field(p) = n
+			return err
+		},
+	}
+}
+
+// andFlag is like flag, but an existing row keeps the flag only if the
+// incoming profile has it too.
+func andFlag(name string, field func(p *scraper.Profile) *bool) column {
+	c := flag(name, field)
+	c.update = "CASE WHEN excluded.%[1]s != '' AND profiles.%[1]s != '' THEN '1' ELSE '' END"
+	return c
+}
+
+func flag(name string, field func(p *scraper.Profile) *bool) column {
+	return column{
+		name: name,
+		get: func(p scraper.Profile) (string, error) {
+			if *field(&p) {
+				return "1", nil
+			}
+			return "", nil
+		},
+		set: func(p *scraper.Profile, v string) error {
+			*field(p) = v != ""
+			return nil
+		},
+	}
+}
+
+// columns lists the stored Profile fields besides id. Open adds any column
+// missing from an existing database, so new fields only need an entry here.
+var columns = []column{
+	text("name", func(p *scraper.Profile) *string { return &p.Name }),
+	text("title", func(p *scraper.Profile) *string { return &p.Title }),
+	text("company", func(p *scraper.Profile) *string { return &p.Company }),
+	text("email", func(p *scraper.Profile) *string { return &p.Email }),
+	text("location", func(p *scraper.Profile) *string { return &p.Location }),
+	text("linkedin_url", func(p *scraper.Profile) *string { return &p.LinkedInURL }),
+	list("possible_linkedin_urls", func(p *scraper.Profile) *[]string { return &p.PossibleLinkedInURLs }),
+	flag("linkedin_searched", func(p *scraper.Profile) *bool { return &p.LinkedInSearched }),
+	number("linkedin_status", func(p *scraper.Profile) *int { return &p.LinkedInStatus }),
+	text("twitter", func(p *scraper.Profile) *string { return &p.Twitter }),
+	flag("twitter_searched", func(p *scraper.Profile) *bool { return &p.TwitterSearched }),
+	text("website", func(p *scraper.Profile) *string { return &p.Website }),
+	text("time_zone", func(p *scraper.Profile) *string { return &p.TimeZone }),
+	text("role", func(p *scraper.Profile) *string { return &p.Role }),
+	text("industry", func(p *scraper.Profile) *string { return &p.Industry }),
+	text("company_size", func(p *scraper.Profile) *string { return &p.CompanySize }),
+	timestamp("registered_at", func(p *scraper.Profile) *time.Time { return &p.RegisteredAt }),
+	list("countries", func(p *scraper.Profile) *[]string { return &p.Countries }),
+	list("interests", func(p *scraper.Profile) *[]string { return &p.Interests }),
+	list("event_ids", func(p *scraper.Profile) *[]string { return &p.EventIDs }),
+	sources("sources", func(p *scraper.Profile) *map[string]string { return &p.Sources }),
+	andFlag("incomplete", func(p *scraper.Profile) *bool { return &p.Incomplete }),
+}
+
+// Open opens (creating if needed) the SQLite database at path and makes
+// sure the profiles table has every known column.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS profiles (
+		id TEXT PRIMARY KEY,
+		first_seen TEXT NOT NULL,
+		last_seen TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.Query(`PRAGMA table_info(profiles)`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		if existing[c.name] {
+			continue
+		}
+		if _, err := s.db.Exec(fmt.Sprintf(`ALTER TABLE profiles ADD COLUMN %s TEXT NOT NULL DEFAULT ''`, c.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upsert inserts profiles not yet in the table and updates existing ones,
+// bumping last_seen. An empty incoming value never overwrites a stored
+// one, so data from earlier runs (such as LinkedIn matches) is kept; the
+// incomplete flag is the exception and clears once either side is complete.
+// Profiles without an ID are skipped. It reports how many rows were new.
+func (s *Store) Upsert(ctx context.Context, profiles []scraper.Profile) (inserted, updated int, err error) {
+	names := []string{"id"}
+	placeholders := []string{"?"}
+	updates := make([]string, 0, len(columns)+1)
+	for _, c := range columns {
+		names = append(names, c.name)
+		placeholders = append(placeholders, "?")
+		update := c.update
+		if update == "" {
+			update = "CASE WHEN excluded.%[1]s != '' THEN excluded.%[1]s ELSE profiles.%[1]s END"
+		}
+		updates = append(updates, fmt.Sprintf("%[1]s = "+update, c.name))
+	}
+	names = append(names, "first_seen", "last_seen")
+	placeholders = append(placeholders, "?", "?")
+	updates = append(updates, "last_seen = excluded.last_seen")
+
+	query := fmt.Sprintf(
+		`INSERT INTO profiles (%s) VALUES (%s) ON CONFLICT(id) DO UPDATE SET %s`,
+		strings.Join(names, ", "),
+		strings.Join(placeholders, ", "),
+		strings.Join(updates, ", "),
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stmt.Close()
+
+	seen := time.Now().UTC().Format(time.RFC3339)
+
+	for _, p := range profiles {
+		if p.ID == "" {
+			continue
+		}
+
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM profiles WHERE id = ?)`, p.ID).Scan(&exists)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		args := []any{p.ID}
+		for _, c := range columns {
+			v, err := c.get(p)
+			if err != nil {
+				return 0, 0, fmt.Errorf("encoding %s for %s: %w", c.name, p.ID, err)
+			}
+			args = append(args, v)
+		}
+		args = append(args, seen, seen)
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return 0, 0, fmt.Errorf("upserting %s: %w", p.ID, err)
+		}
+
+		if exists {
+			updated++
+		} else {
+			inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return inserted, updated, nil
+}
+
+// Profiles returns every stored profile, oldest first_seen first.
+func (s *Store) Profiles(ctx context.Context) ([]scraper.Profile, error) {
+	names := []string{"id"}
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %s FROM profiles ORDER BY first_seen, id`,
+		strings.Join(names, ", "),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []scraper.Profile
+	for rows.Next() {
+		values := make([]string, len(names))
+		dest := make([]any, len(names))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		p := scraper.Profile{ID: values[0]}
+		for i, c := range columns {
+			if err := c.set(&p, values[i+1]); err != nil {
+				return nil, fmt.Errorf("decoding %s for %s: %w", c.name, p.ID, err)
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
@@ -0,0 +1,131 @@
+// Package throttle paces requests from the rate-limit headers an API
+// returns, so request delays don't have to be tuned by hand.
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Throttle spreads the requests an API says are left in its current window
+// evenly over the time until the window resets, and pauses until the reset
+// once the quota is used up. It understands the common
+// X-RateLimit-Remaining / X-RateLimit-Reset headers and the unprefixed
+// RateLimit-Remaining / RateLimit-Reset variants. Until a response carries
+// these headers, Wait returns immediately.
+//
+// The zero value is ready to use and safe for concurrent use.
+type Throttle struct {
+	mu         sync.Mutex
+	delay      time.Duration
+	last       time.Time // when the most recently admitted request goes out
+	pauseUntil time.Time
+}
+
+// Observe updates the pacing from a response's headers and returns how long
+// requests will be paused if the quota is exhausted, or 0 otherwise.
+// Responses without rate-limit headers leave the current pacing unchanged.
+func (t *Throttle) Observe(h http.Header) time.Duration {
+	remaining, ok := headerInt(h, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if !ok {
+		return 0
+	}
+	reset, ok := parseReset(h, time.Now())
+	if !ok {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if remaining <= 0 {
+		t.pauseUntil = time.Now().Add(reset)
+		return reset
+	}
+
+	t.delay = reset / time.Duration(remaining)
+	// Quota left means a fresh window, ending any earlier pause.
+	t.pauseUntil = time.Time{}
+	return 0
+}
+
+// Wait blocks until the next request may be sent under the current pacing,
+// or until ctx is done.
+func (t *Throttle) Wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	at := now
+	if next := t.last.Add(t.delay); next.After(at) {
+		at = next
+	}
+	if t.pauseUntil.After(at) {
+		at = t.pauseUntil
+	}
+	// Claim the slot now so concurrent callers queue up behind each
+	// other instead of all going out at once.
+	t.last = at
+	t.mu.Unlock()
+
+	wait := at.Sub(now)
+
+	if wait == 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Delay returns the current spacing between requests.
+func (t *Throttle) Delay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delay
+}
+
+// headerInt returns the first of names present in h as an integer.
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		v := strings.TrimSpace(h.Get(name))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// parseReset returns the time left until the rate-limit window resets.
+// The reset header is either seconds from now or, for values that only make
+// sense as a timestamp, Unix seconds.
+func parseReset(h http.Header, now time.Time) (time.Duration, bool) {
+	n, ok := headerInt(h, "X-RateLimit-Reset", "RateLimit-Reset")
+	if !ok || n < 0 {
+		return 0, false
+	}
+
+	// Anything past a year of seconds is a Unix timestamp.
+	if n > 365*24*60*60 {
+		d := time.Unix(int64(n), 0).Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return time.Duration(n) * time.Second, true
+}